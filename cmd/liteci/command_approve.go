@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sourceplane/liteci/internal/runner"
+	"github.com/spf13/cobra"
+)
+
+var (
+	approvePlanFile string
+	approveFile     string
+	approveBy       string
+)
+
+var approveCmd = &cobra.Command{
+	Use:   "approve <job-id>...",
+	Short: "Approve gated jobs of a plan",
+	Long:  "Record approvals for jobs gated by an approval policy. A run waiting on those gates picks the approvals up and continues.",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return approveJobs(args)
+	},
+}
+
+func registerApproveCommand(root *cobra.Command) {
+	root.AddCommand(approveCmd)
+
+	approveCmd.Flags().StringVarP(&approvePlanFile, "plan", "p", "plan.json", "Path to plan file (json or yaml)")
+	approveCmd.Flags().StringVar(&approveFile, "approvals", "", "Approvals file (default: next to the plan, e.g. plan.approvals.json)")
+	approveCmd.Flags().StringVar(&approveBy, "by", os.Getenv("USER"), "Name recorded as the approver")
+}
+
+func approveJobs(jobIDs []string) error {
+	plan, err := loadPlan(approvePlanFile)
+	if err != nil {
+		return err
+	}
+
+	path := approveFile
+	if path == "" {
+		path = runner.DefaultApprovalsPath(approvePlanFile)
+	}
+	if err := runner.Approve(path, plan, jobIDs, approveBy); err != nil {
+		return err
+	}
+
+	for _, jobID := range jobIDs {
+		fmt.Printf("✓ Approved %s\n", jobID)
+	}
+	fmt.Printf("✓ Saved to: %s\n", path)
+	return nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/sourceplane/liteci/internal/diff"
+	"github.com/spf13/cobra"
+)
+
+// diffChangedExitCode is the exit code of plan diff when the plans differ;
+// 1 is left for errors
+const diffChangedExitCode = 2
+
+// errPlansDiffer is returned by plan diff when the plans differ; main maps it
+// to diffChangedExitCode
+var errPlansDiffer = errors.New("plans differ")
+
+var diffFormat string
+
+var planDiffCmd = &cobra.Command{
+	Use:   "diff <old-plan> <new-plan>",
+	Short: "Compare two plan files",
+	Long: `Compare two plan files and report added and removed jobs, changed steps
+(as line diffs of the rendered commands), inputs, env and other job fields, and
+dependency edges that were added or removed.
+
+Exits 0 when the plans are the same, 2 when they differ and 1 on errors.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := diffPlans(args[0], args[1])
+		if errors.Is(err, errPlansDiffer) {
+			// The diff was printed; only the exit code is left to report
+			cmd.SilenceErrors = true
+			cmd.SilenceUsage = true
+		}
+		return err
+	},
+}
+
+func registerDiffCommand(plan *cobra.Command) {
+	plan.AddCommand(planDiffCmd)
+
+	planDiffCmd.Flags().StringVarP(&diffFormat, "format", "f", "text", "Output format: text or json")
+}
+
+func diffPlans(oldPath, newPath string) error {
+	if diffFormat != "text" && diffFormat != "json" {
+		return fmt.Errorf("unsupported --format %q (expected text or json)", diffFormat)
+	}
+
+	oldPlan, err := readPlan(oldPath)
+	if err != nil {
+		return err
+	}
+	newPlan, err := readPlan(newPath)
+	if err != nil {
+		return err
+	}
+
+	d := diff.Plans(oldPlan, newPlan)
+
+	if diffFormat == "json" {
+		data, err := json.MarshalIndent(d, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to render diff: %w", err)
+		}
+		if _, err := os.Stdout.Write(append(data, '\n')); err != nil {
+			return err
+		}
+	} else if err := d.WriteText(os.Stdout); err != nil {
+		return err
+	}
+
+	if d.HasChanges() {
+		return errPlansDiffer
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sourceplane/liteci/internal/export"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportPlanFile string
+	exportTarget   string
+	exportOutput   string
+	exportName     string
+	exportRunsOn   string
+	exportImage    string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export a compiled plan as another CI system's pipeline",
+	Long:  "Convert a plan into a pipeline definition for another CI system, e.g. a GitHub Actions workflow or an Argo Workflow.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return exportPlan()
+	},
+}
+
+func registerExportCommand(root *cobra.Command) {
+	root.AddCommand(exportCmd)
+
+	exportCmd.Flags().StringVarP(&exportPlanFile, "plan", "p", "plan.json", "Path to plan file (json or yaml)")
+	exportCmd.Flags().StringVarP(&exportTarget, "target", "t", "", "Export target ("+strings.Join(export.Targets(), ", ")+")")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "-", "Output file path (- for stdout), e.g. .github/workflows/deploy.yml, .gitlab-ci.yml or workflow.yaml")
+	exportCmd.Flags().StringVar(&exportName, "name", "", "Pipeline name (default: plan name)")
+	exportCmd.Flags().StringVar(&exportRunsOn, "runs-on", "", "Runner label the jobs run on (github-actions; default: "+export.DefaultGitHubRunsOn+")")
+	exportCmd.Flags().StringVar(&exportImage, "image", "", "Container image the jobs run in (gitlab-ci, argo-workflow, tekton-pipeline; the Kubernetes targets default to "+export.DefaultImage+")")
+	exportCmd.MarkFlagRequired("target")
+}
+
+func exportPlan() error {
+	plan, err := loadPlan(exportPlanFile)
+	if err != nil {
+		return err
+	}
+
+	exporter, err := export.New(exportTarget, export.Options{Name: exportName, RunsOn: exportRunsOn, Image: exportImage, Warnings: os.Stderr})
+	if err != nil {
+		return err
+	}
+
+	data, err := exporter.Export(plan)
+	if err != nil {
+		return fmt.Errorf("failed to export plan to %s: %w", exportTarget, err)
+	}
+
+	if exportOutput == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+
+	dir := filepath.Dir(exportOutput)
+	if dir != "." && dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(exportOutput, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", exportOutput, err)
+	}
+
+	fmt.Printf("✓ Exported %d jobs to %s (%s)\n", len(plan.Jobs), exportOutput, exportTarget)
+	return nil
+}
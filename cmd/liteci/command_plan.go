@@ -12,13 +12,19 @@ var planCmd = &cobra.Command{
 
 func registerPlanCommand(root *cobra.Command) {
 	root.AddCommand(planCmd)
+	registerDiffCommand(planCmd)
 
 	planCmd.Flags().StringVarP(&intentFile, "intent", "i", "intent.yaml", "Intent file path")
-	planCmd.Flags().StringVarP(&outputFile, "output", "o", "plan.json", "Output plan file path")
-	planCmd.Flags().StringVarP(&outputFormat, "format", "f", "json", "Output format (json/yaml)")
+	planCmd.Flags().StringVarP(&outputFile, "output", "o", "plan.json", "Output plan file path (- for stdout)")
+	planCmd.Flags().StringVarP(&outputFormat, "format", "f", "", "Output format: json, yaml or canonical-json (default: from the output file extension)")
 	planCmd.Flags().BoolVar(&debugMode, "debug", false, "Enable debug output")
-	planCmd.Flags().StringVarP(&environment, "env", "e", "", "Filter by environment (optional)")
-	planCmd.Flags().StringVarP(&viewPlan, "view", "v", "", "View plan (dag/dependencies/component=NAME)")
+	planCmd.Flags().StringSliceVarP(&environments, "env", "e", nil, "Plan only these environments (names or globs, comma-separated or repeated; default: all)")
+	planCmd.Flags().StringVar(&jobName, "job", "", "Job to plan from each composition (e.g. deploy, rollback, diff; default: composition default job)")
+	planCmd.Flags().BoolVar(&includeDeps, "include-dependencies", false, "Plan dependency targets that are outside --env or not selected by their environment's selectors")
+	planCmd.Flags().StringVarP(&viewPlan, "view", "v", "", "View plan (dag/dependencies/component=NAME/mermaid/dot)")
+	planCmd.Flags().StringVar(&reportFile, "report", "", "Also write a self-contained HTML report of the plan to this path (e.g. plan.html)")
+	planCmd.Flags().StringVar(&signKeyFile, "sign-key", "", "Sign the plan with this ed25519 private key (PEM), writing a detached signature next to it (e.g. plan.json.sig)")
+	planCmd.Flags().BoolVar(&strictPolicies, "strict-policies", false, "Fail on group or environment policies that are not rules instead of warning about them")
 	planCmd.Flags().BoolVar(&changedOnly, "changed", false, "Show only changed components (requires git)")
 	planCmd.Flags().StringVar(&baseBranch, "base", "", "Base ref for changed detection (default: main)")
 	planCmd.Flags().StringVar(&headRef, "head", "", "Head ref for changed detection (usually HEAD)")
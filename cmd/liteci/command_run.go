@@ -1,13 +1,17 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/sourceplane/liteci/internal/model"
+	"github.com/sourceplane/liteci/internal/render"
 	"github.com/sourceplane/liteci/internal/runner"
+	"github.com/sourceplane/liteci/internal/signing"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
@@ -16,6 +20,18 @@ var (
 	runPlanFile string
 	runExecute  bool
 	runWorkDir  string
+
+	runMaxParallel     int
+	runRetryBackoff    time.Duration
+	runRetryBackoffMax time.Duration
+	runKeepGoing       bool
+	runStateFile       string
+	runResume          bool
+	runApprovalsFile   string
+	runApprovalTimeout time.Duration
+	runNoPrompt        bool
+	runVerifyKey       string
+	runAllowNoDigest   bool
 )
 
 var runCmd = &cobra.Command{
@@ -33,22 +49,113 @@ func registerRunCommand(root *cobra.Command) {
 	runCmd.Flags().StringVarP(&runPlanFile, "plan", "p", "plan.json", "Path to plan file (json or yaml)")
 	runCmd.Flags().BoolVarP(&runExecute, "execute", "x", false, "Actually execute commands (default is dry-run)")
 	runCmd.Flags().StringVar(&runWorkDir, "workdir", ".", "Base working directory for relative job paths")
+	runCmd.Flags().IntVar(&runMaxParallel, "max-parallel", 1, "Maximum number of jobs to run concurrently")
+	runCmd.Flags().DurationVar(&runRetryBackoff, "retry-backoff", runner.DefaultRetryBackoff, "Delay before the first retry of a failed step or job (doubles on each retry)")
+	runCmd.Flags().DurationVar(&runRetryBackoffMax, "retry-backoff-max", runner.DefaultRetryBackoffMax, "Upper bound for the delay between retries")
+	runCmd.Flags().BoolVarP(&runKeepGoing, "keep-going", "k", false, "Keep running independent jobs after a failure; only dependents of failed jobs are skipped")
+	runCmd.Flags().StringVar(&runStateFile, "state", "", "Run state file (default: next to the plan, e.g. plan.state.json)")
+	runCmd.Flags().BoolVar(&runResume, "resume", false, "Resume a previous run from its state file, skipping jobs that already succeeded")
+	runCmd.Flags().StringVar(&runApprovalsFile, "approvals", "", "Approvals file polled for gated jobs (default: next to the plan, e.g. plan.approvals.json)")
+	runCmd.Flags().DurationVar(&runApprovalTimeout, "approval-timeout", 0, "Fail gated jobs not approved within this duration (0 waits indefinitely)")
+	runCmd.Flags().BoolVar(&runNoPrompt, "no-prompt", false, "Never prompt for approvals on the terminal; wait for liteci approve instead")
+	runCmd.Flags().BoolVar(&runAllowNoDigest, "allow-missing-digest", false, "Run a plan without metadata.digest (hand-written or from an older liteci), whose content cannot be verified")
+	runCmd.Flags().StringVar(&runVerifyKey, "verify-key", "", "Refuse to run unless the plan's detached signature (e.g. plan.json.sig) verifies with this ed25519 public key (PEM)")
 }
 
 func runPlan() error {
-	plan, err := loadPlan(runPlanFile)
+	data, err := os.ReadFile(runPlanFile)
+	if err != nil {
+		return fmt.Errorf("failed to read plan file %s: %w", runPlanFile, err)
+	}
+
+	// With --verify-key, only a plan signed by the matching private key runs;
+	// the verified bytes are the ones parsed below
+	if runVerifyKey != "" {
+		key, err := signing.LoadPublicKey(runVerifyKey)
+		if err != nil {
+			return err
+		}
+		if err := signing.Verify(data, signing.SignaturePath(runPlanFile), key); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Plan signature verified (%s)\n", signing.SignaturePath(runPlanFile))
+	}
+
+	plan, err := loadPlanData(runPlanFile, data)
 	if err != nil {
 		return err
 	}
 
+	// Refuse plans edited after liteci plan generated them
+	if plan.Metadata.Digest == "" {
+		if !runAllowNoDigest {
+			return fmt.Errorf("plan has no metadata.digest, so it cannot be verified; regenerate it with liteci plan or pass --allow-missing-digest")
+		}
+		fmt.Println("⚠ Plan has no metadata.digest; cannot verify it was not modified")
+	} else if err := render.VerifyDigest(plan); err != nil {
+		return err
+	}
+
 	dryRun := !runExecute
 	if dryRun {
 		fmt.Println("□ Dry-run mode enabled. Use --execute to run commands.")
 	}
 
+	if runMaxParallel < 1 {
+		return fmt.Errorf("--max-parallel must be at least 1, got %d", runMaxParallel)
+	}
+
 	r := runner.NewRunner(runWorkDir, os.Stdout, os.Stderr, dryRun)
-	if err := r.Run(plan); err != nil {
-		return err
+	r.MaxParallel = runMaxParallel
+	r.RetryBackoff = runRetryBackoff
+	r.RetryBackoffMax = runRetryBackoffMax
+	r.KeepGoing = runKeepGoing
+
+	statePath := runStateFile
+	if statePath == "" {
+		statePath = runner.DefaultStatePath(runPlanFile)
+	}
+	if runResume {
+		state, err := runner.LoadRunState(statePath)
+		if err != nil {
+			return fmt.Errorf("cannot resume: %w", err)
+		}
+		fmt.Printf("□ Resuming run from %s\n", statePath)
+		r.State = state
+	} else {
+		r.State = runner.NewRunState()
+	}
+	r.StatePath = statePath
+
+	approvalsPath := runApprovalsFile
+	if approvalsPath == "" {
+		approvalsPath = runner.DefaultApprovalsPath(runPlanFile)
+	}
+	r.ApprovalsPath = approvalsPath
+	r.ApprovalTimeout = runApprovalTimeout
+	if !runNoPrompt && isTerminal(os.Stdin) {
+		r.Prompt = os.Stdin
+	}
+
+	runErr := r.Run(plan)
+
+	if len(r.Results()) > 0 {
+		fmt.Println()
+		if err := r.WriteSummary(os.Stdout); err != nil {
+			return err
+		}
+	}
+	if runErr != nil {
+		return runErr
+	}
+
+	for _, result := range r.Results() {
+		if result.Status == runner.JobSucceededWithWarnings {
+			fmt.Printf("⚠ Job %s succeeded with warnings:\n", result.JobID)
+			for _, warning := range result.Warnings {
+				fmt.Printf("    %s\n", warning)
+			}
+		}
 	}
 
 	if dryRun {
@@ -60,12 +167,49 @@ func runPlan() error {
 	return nil
 }
 
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
 func loadPlan(path string) (*model.Plan, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read plan file %s: %w", path, err)
 	}
+	return loadPlanData(path, data)
+}
+
+// loadPlanData parses the contents of a plan file that were already read,
+// e.g. to verify their signature first
+func loadPlanData(path string, data []byte) (*model.Plan, error) {
+	plan, err := parsePlan(path, data)
+	if err != nil {
+		return nil, err
+	}
 
+	if len(plan.Jobs) == 0 {
+		return nil, fmt.Errorf("plan contains no jobs")
+	}
+
+	return plan, nil
+}
+
+// readPlan parses a plan file, which may have no jobs
+func readPlan(path string) (*model.Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan file %s: %w", path, err)
+	}
+	return parsePlan(path, data)
+}
+
+// parsePlan decodes a plan as YAML or JSON, picking the format from the path
+func parsePlan(path string, data []byte) (*model.Plan, error) {
 	var plan model.Plan
 	ext := filepath.Ext(path)
 	switch ext {
@@ -74,16 +218,15 @@ func loadPlan(path string) (*model.Plan, error) {
 			return nil, fmt.Errorf("failed to parse YAML plan: %w", err)
 		}
 	default:
-		if err := json.Unmarshal(data, &plan); err != nil {
+		// UseNumber keeps large integer inputs exact for the step environment
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&plan); err != nil {
 			if yamlErr := yaml.Unmarshal(data, &plan); yamlErr != nil {
 				return nil, fmt.Errorf("failed to parse plan file as JSON or YAML: %w", err)
 			}
 		}
 	}
 
-	if len(plan.Jobs) == 0 {
-		return nil, fmt.Errorf("plan contains no jobs")
-	}
-
 	return &plan, nil
 }
@@ -13,16 +13,22 @@ var (
 	outputFile   string
 	outputFormat string
 	debugMode    bool
-	environment  string
+	environments []string
+	jobName      string
+	includeDeps  bool
 	longFormat   bool
 	expandJobs   bool
 	viewPlan     string
+	reportFile   string
+	signKeyFile  string
 	changedOnly  bool
 	baseBranch   string
 	headRef      string
 	changedFiles []string
 	uncommitted  bool
 	untracked    bool
+
+	strictPolicies bool
 )
 
 var rootCmd = &cobra.Command{
@@ -42,10 +48,17 @@ var rootCmd = &cobra.Command{
 }
 
 func init() {
+	rootCmd.Version = version
+	if commit != "" {
+		rootCmd.Version += " (" + commit + ")"
+	}
+
 	rootCmd.PersistentFlags().StringVarP(&configDir, "config-dir", "c", "", "Config directory for JobRegistry definitions (or set LITECI_CONFIG_DIR; use * or ** for recursive scanning)")
 
 	registerPlanCommand(rootCmd)
 	registerRunCommand(rootCmd)
+	registerApproveCommand(rootCmd)
+	registerExportCommand(rootCmd)
 	registerValidateCommand(rootCmd)
 	registerDebugCommand(rootCmd)
 	registerCompositionsCommand(rootCmd)
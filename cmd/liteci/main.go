@@ -1,7 +1,10 @@
 package main
 
 import (
+	"crypto/ed25519"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"sort"
 	"strings"
@@ -12,60 +15,111 @@ import (
 	"github.com/sourceplane/liteci/internal/model"
 	"github.com/sourceplane/liteci/internal/normalize"
 	"github.com/sourceplane/liteci/internal/planner"
+	"github.com/sourceplane/liteci/internal/policy"
 	"github.com/sourceplane/liteci/internal/render"
+	"github.com/sourceplane/liteci/internal/signing"
 )
 
 func generatePlan() error {
-	fmt.Println("□ Loading intent...")
+	// When the plan or a view goes to stdout, progress messages go to stderr
+	// so the output can be piped
+	if outputFile == "-" && viewPlan != "" {
+		return fmt.Errorf("--view and -o - both write to stdout; write the plan to a file with -o")
+	}
+	var progress io.Writer = os.Stdout
+	if outputFile == "-" || viewPlan != "" {
+		progress = os.Stderr
+	}
+
+	switch outputFormat {
+	case "", render.FormatJSON, render.FormatYAML, "yml", render.FormatCanonicalJSON:
+	default:
+		return fmt.Errorf("unsupported --format %q (expected %s, %s or %s)", outputFormat, render.FormatJSON, render.FormatYAML, render.FormatCanonicalJSON)
+	}
+
+	var signKey ed25519.PrivateKey
+	if signKeyFile != "" {
+		if outputFile == "-" {
+			return fmt.Errorf("--sign-key needs a plan file to sign; use -o instead of writing to stdout")
+		}
+		key, err := signing.LoadPrivateKey(signKeyFile)
+		if err != nil {
+			return err
+		}
+		signKey = key
+	}
+
+	fmt.Fprintln(progress, "□ Loading intent...")
 	intent, err := loader.LoadIntent(intentFile)
 	if err != nil {
 		return fmt.Errorf("failed to load intent: %w", err)
 	}
 
-	fmt.Println("□ Loading compositions...")
+	fmt.Fprintln(progress, "□ Loading compositions...")
 	compositionRegistry, err := loader.LoadCompositionsFromDir(configDir)
 	if err != nil {
 		return fmt.Errorf("failed to load compositions from %s: %w", configDir, err)
 	}
 
-	// Build CompositionInfo map for the planner with default jobs
+	// Build CompositionInfo map for the planner; JobBindings (binding.yaml)
+	// decide the default, available and required jobs when present
 	compositionInfos := make(map[string]*planner.CompositionInfo)
 	for typeName, composition := range compositionRegistry.Types {
-		// Use first job as default if available
-		var defaultJob *model.JobSpec
-		if len(composition.Jobs) > 0 {
-			defaultJob = &composition.Jobs[0]
-		}
 		compositionInfos[typeName] = &planner.CompositionInfo{
-			Type:       typeName,
-			DefaultJob: defaultJob,
+			Type:         typeName,
+			DefaultJob:   composition.DefaultJob(),
+			Jobs:         composition.AvailableJobs(),
+			RequiredJobs: composition.RequiredJobs(),
 		}
 	}
 
-	fmt.Println("□ Normalizing intent...")
+	fmt.Fprintln(progress, "□ Normalizing intent...")
 	normalized, err := normalize.NormalizeIntent(intent)
 	if err != nil {
 		return fmt.Errorf("failed to normalize intent: %w", err)
 	}
 
-	fmt.Println("□ Validating components against composition schemas...")
+	fmt.Fprintln(progress, "□ Validating components against composition schemas...")
 	if err := compositionRegistry.ValidateAllComponents(normalized); err != nil {
 		return fmt.Errorf("component validation failed: %w", err)
 	}
 
-	fmt.Println("□ Expanding (env × component)...")
+	fmt.Fprintln(progress, "□ Expanding (env × component)...")
 	expander := expand.NewExpander(normalized)
+	if len(environments) > 0 {
+		if err := expander.RestrictEnvironments(environments); err != nil {
+			return fmt.Errorf("invalid --env: %w", err)
+		}
+	}
 	instances, err := expander.Expand()
 	if err != nil {
 		return fmt.Errorf("failed to expand intent: %w", err)
 	}
 
+	fmt.Fprintln(progress, "□ Resolving dependency scopes...")
+	included, err := expander.ResolveDependencyScopes(instances, includeDeps)
+	if err != nil {
+		return err
+	}
+	for _, inst := range included {
+		fmt.Fprintf(progress, "  + Including %s@%s (%s)\n", inst.ComponentName, inst.Environment, strings.Join(inst.SelectedBy, ", "))
+	}
+
 	// Filter instances if --changed flag is set
+	inclusion := make(map[string]string)
+	var changeFilter *model.ChangeFilter
 	if changedOnly {
 		changeOptions, err := buildChangeOptions()
 		if err != nil {
 			return err
 		}
+		changeFilter = &model.ChangeFilter{
+			Base:        changeOptions.Base,
+			Head:        changeOptions.Head,
+			Files:       changeOptions.Files,
+			Uncommitted: changeOptions.Uncommitted,
+			Untracked:   changeOptions.Untracked,
+		}
 
 		changedSet, err := changedFilesSet(changeOptions)
 		if err != nil {
@@ -100,6 +154,16 @@ func generatePlan() error {
 		// Use dependency resolver to include all required dependencies
 		resolver := expand.NewDependencyResolver(normalized)
 		includedComps := resolver.ResolveComponentSet(changedComps)
+		changed, dependencies, dependents := resolver.CategorizeDependencies(changedComps)
+		for comp := range dependents {
+			inclusion[comp] = render.InclusionDependent
+		}
+		for comp := range dependencies {
+			inclusion[comp] = render.InclusionDependency
+		}
+		for comp := range changed {
+			inclusion[comp] = render.InclusionChanged
+		}
 
 		// Filter instances to include changed components and their dependencies
 		for envName := range instances {
@@ -113,38 +177,51 @@ func generatePlan() error {
 		}
 	}
 
+	fmt.Fprintln(progress, "□ Checking composition constraints...")
+	if err := checkCompositionConstraints(progress, compositionRegistry, normalized, instances); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(progress, "□ Evaluating policies...")
+	if err := evaluatePolicies(progress, normalized, instances); err != nil {
+		return err
+	}
+
 	if debugMode {
 		count := 0
 		for _, envInsts := range instances {
 			count += len(envInsts)
 		}
-		fmt.Printf("  Generated %d component instances\n", count)
+		fmt.Fprintf(progress, "  Generated %d component instances\n", count)
 	}
 
-	fmt.Println("□ Binding jobs and resolving dependencies...")
+	fmt.Fprintln(progress, "□ Binding jobs and resolving dependencies...")
 	jobPlanner := planner.NewJobPlanner(compositionInfos)
+	if jobName != "" {
+		jobPlanner.SelectJob(jobName)
+	}
 	jobInstances, err := jobPlanner.PlanJobs(instances)
 	if err != nil {
 		return fmt.Errorf("failed to plan jobs: %w", err)
 	}
 
-	fmt.Println("□ Detecting cycles...")
+	fmt.Fprintln(progress, "□ Detecting cycles...")
 	dag := planner.NewJobGraph(jobInstances)
 	if err := dag.DetectCycles(); err != nil {
 		return fmt.Errorf("cycle detection failed: %w", err)
 	}
 
-	fmt.Println("□ Topologically sorting...")
+	fmt.Fprintln(progress, "□ Topologically sorting...")
 	sorted, err := dag.TopologicalSort()
 	if err != nil {
 		return fmt.Errorf("topological sort failed: %w", err)
 	}
 
 	if debugMode {
-		fmt.Printf("  Sorted %d jobs\n", len(sorted))
+		fmt.Fprintf(progress, "  Sorted %d jobs\n", len(sorted))
 	}
 
-	fmt.Println("□ Rendering plan...")
+	fmt.Fprintln(progress, "□ Rendering plan...")
 
 	// Build JobRegistry bindings map (model -> JobRegistry name)
 	jobBindings := make(map[string]string)
@@ -156,18 +233,57 @@ func generatePlan() error {
 
 	renderer := render.NewRenderer()
 	plan := renderer.RenderPlanWithOrder(intent.Metadata, jobInstances, jobBindings, sorted)
+	plan.Metadata.Environments = environments
+
+	provenance, err := buildProvenance(compositionRegistry, plan, changeFilter)
+	if err != nil {
+		return err
+	}
+	plan.Metadata.Provenance = provenance
+	if err := render.StampDigest(plan); err != nil {
+		return err
+	}
 
 	if debugMode {
-		fmt.Println("\n" + renderer.DebugDump(plan))
+		fmt.Fprintln(progress, "\n"+renderer.DebugDump(plan))
 	}
 
-	// Write plan to file
-	if err := renderer.WritePlan(plan, outputFile); err != nil {
-		return fmt.Errorf("failed to write plan: %w", err)
+	// Write plan to file, or to stdout with -o -
+	if outputFile == "-" {
+		if err := renderer.WritePlanTo(os.Stdout, plan, outputFormat); err != nil {
+			return fmt.Errorf("failed to write plan: %w", err)
+		}
+		fmt.Fprintf(progress, "✓ Plan generated with %d jobs\n", len(plan.Jobs))
+	} else {
+		if err := renderer.WritePlan(plan, outputFile, outputFormat); err != nil {
+			return fmt.Errorf("failed to write plan: %w", err)
+		}
+		fmt.Fprintf(progress, "✓ Plan generated with %d jobs\n", len(plan.Jobs))
+		fmt.Fprintf(progress, "✓ Saved to: %s\n", outputFile)
+
+		if signKey != nil {
+			data, err := os.ReadFile(outputFile)
+			if err != nil {
+				return fmt.Errorf("failed to read plan for signing: %w", err)
+			}
+			sigPath := signing.SignaturePath(outputFile)
+			if err := signing.WriteSignature(sigPath, data, signKey); err != nil {
+				return err
+			}
+			fmt.Fprintf(progress, "✓ Signed: %s\n", sigPath)
+		}
 	}
 
-	fmt.Printf("✓ Plan generated with %d jobs\n", len(plan.Jobs))
-	fmt.Printf("✓ Saved to: %s\n", outputFile)
+	if reportFile != "" {
+		report := render.NewPlanReport(plan, instances)
+		for comp, reason := range inclusion {
+			report.SetInclusion(comp, reason)
+		}
+		if err := report.Write(reportFile); err != nil {
+			return err
+		}
+		fmt.Fprintf(progress, "✓ Report saved to: %s\n", reportFile)
+	}
 
 	// Handle --view flag
 	if viewPlan != "" {
@@ -179,6 +295,11 @@ func generatePlan() error {
 			output = viewer.ViewDAG()
 		case viewPlan == "dependencies":
 			output = viewer.ViewDependencies()
+		case viewPlan == "mermaid":
+			// Fenced so it renders when pasted into GitHub or GitLab markdown
+			output = "```mermaid\n" + viewer.ViewMermaid() + "```"
+		case viewPlan == "dot":
+			output = strings.TrimSuffix(viewer.ViewDOT(), "\n")
 		case strings.HasPrefix(viewPlan, "component="):
 			componentName := strings.TrimPrefix(viewPlan, "component=")
 			output = viewer.ViewByComponent(componentName)
@@ -187,9 +308,67 @@ func generatePlan() error {
 			output = viewer.ViewDAG()
 		}
 
-		fmt.Println("\n" + output)
+		fmt.Println(output)
+	}
+
+	return nil
+}
+
+// checkCompositionConstraints rejects component instances whose composition's
+// JobBinding constraints are not met by the environment they are planned in,
+// and warns once per constraint an environment gives nothing to check against
+func checkCompositionConstraints(progress io.Writer, registry *loader.CompositionRegistry, normalized *model.NormalizedIntent, instances map[string][]*model.ComponentInstance) error {
+	envNames := make([]string, 0, len(instances))
+	for envName := range instances {
+		envNames = append(envNames, envName)
+	}
+	sort.Strings(envNames)
+
+	var violations []string
+	warned := make(map[string]bool)
+	for _, envName := range envNames {
+		env := normalized.Environments[envName]
+		for _, inst := range instances[envName] {
+			warnings, err := registry.ValidateConstraints(inst.Type, envName, env)
+			if err != nil {
+				violations = append(violations, fmt.Sprintf("%s@%s: %v", inst.ComponentName, envName, err))
+			}
+			for _, warning := range warnings {
+				if !warned[warning] {
+					warned[warning] = true
+					fmt.Fprintf(progress, "  ⚠ %s\n", warning)
+				}
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("composition constraints not met:\n  %s", strings.Join(violations, "\n  "))
 	}
+	return nil
+}
 
+// evaluatePolicies rejects the plan when any component instance violates a
+// rule declared in its group's or environment's policies, and warns about
+// policies that are not rules
+func evaluatePolicies(progress io.Writer, normalized *model.NormalizedIntent, instances map[string][]*model.ComponentInstance) error {
+	engine := policy.NewEngine(normalized)
+	engine.Strict = strictPolicies
+	violations, warnings, err := engine.Evaluate(instances)
+	if err != nil {
+		return fmt.Errorf("failed to evaluate policies: %w", err)
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(progress, "  ⚠ %s\n", warning)
+	}
+
+	if len(violations) > 0 {
+		messages := make([]string, 0, len(violations))
+		for _, v := range violations {
+			messages = append(messages, v.String())
+		}
+		return fmt.Errorf("%d policy violation(s):\n  %s", len(violations), strings.Join(messages, "\n  "))
+	}
 	return nil
 }
 
@@ -231,8 +410,35 @@ func debugIntent() error {
 	}
 
 	fmt.Printf("Environments: %d\n", len(normalized.Environments))
-	for name, env := range normalized.Environments {
-		fmt.Printf("  - %s: %d components, policies=%v\n", name, len(env.Selectors.Components), env.Policies)
+	envNames := make([]string, 0, len(normalized.Environments))
+	for name := range normalized.Environments {
+		envNames = append(envNames, name)
+	}
+	sort.Strings(envNames)
+	for _, name := range envNames {
+		env := normalized.Environments[name]
+		selections, err := expand.SelectComponents(name, env, normalized.ComponentIndex)
+		if err != nil {
+			return err
+		}
+
+		selected := 0
+		for _, selection := range selections {
+			if selection.Selected() {
+				selected++
+			}
+		}
+		fmt.Printf("  - %s: %d components, policies=%v\n", name, selected, env.Policies)
+
+		// Show why each component is in or out of the environment
+		for _, selection := range selections {
+			switch {
+			case selection.Selected():
+				fmt.Printf("      ✓ %s: %s\n", selection.Component, selection.Describe())
+			case selection.ExcludedBy != "":
+				fmt.Printf("      ✗ %s: %s\n", selection.Component, selection.Describe())
+			}
+		}
 	}
 
 	fmt.Printf("Components: %d\n", len(normalized.Components))
@@ -510,8 +716,17 @@ func printComponentDetails(comp *expand.ComponentMerged) {
 	}
 }
 
+// Set by the release build (-X main.version=... -X main.commit=...)
+var (
+	version = "dev"
+	commit  = ""
+)
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
+		if errors.Is(err, errPlansDiffer) {
+			os.Exit(diffChangedExitCode)
+		}
 		os.Exit(1)
 	}
 }
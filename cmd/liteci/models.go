@@ -60,7 +60,7 @@ func ExtractModelInfo(modelName string, composition *loader.Composition, configD
 	// Extract JobRegistry metadata
 	if len(composition.Jobs) > 0 {
 		// Build list of all available jobs from the registry
-		for i, job := range composition.Jobs {
+		for _, job := range composition.Jobs {
 			scope := ""
 			if len(job.Labels) > 0 {
 				if s, ok := job.Labels["scope"]; ok {
@@ -76,14 +76,15 @@ func ExtractModelInfo(modelName string, composition *loader.Composition, configD
 				Timeout:     job.Timeout,
 			}
 			info.AvailableJobs = append(info.AvailableJobs, bindingInfo)
-
-			// First job is the default
-			if i == 0 {
-				info.DefaultJobName = job.Name
-			}
 		}
 	}
 
+	// The binding's defaultJob wins over the first job
+	defaultJob := composition.DefaultJob()
+	if defaultJob != nil {
+		info.DefaultJobName = defaultJob.Name
+	}
+
 	// Extract schema metadata
 	if composition.Schema != nil {
 		info.Title = fmt.Sprintf("%s Model", strings.ToTitle(strings.ToLower(modelName)))
@@ -122,9 +123,9 @@ func ExtractModelInfo(modelName string, composition *loader.Composition, configD
 		}
 	}
 
-	// Extract job metadata (from first job / default job)
-	if len(composition.Jobs) > 0 {
-		job := &composition.Jobs[0] // Use first job as default
+	// Extract job metadata from the default job
+	if defaultJob != nil {
+		job := defaultJob
 		info.JobName = job.Name
 		info.JobDescription = job.Description
 
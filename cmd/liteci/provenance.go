@@ -0,0 +1,69 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/sourceplane/liteci/internal/git"
+	"github.com/sourceplane/liteci/internal/loader"
+	"github.com/sourceplane/liteci/internal/model"
+)
+
+// buildProvenance records the liteci build, the digests of the intent and of
+// the composition files the plan's jobs use, the git revision and the change
+// detection options the plan was compiled with
+func buildProvenance(registry *loader.CompositionRegistry, plan *model.Plan, changes *model.ChangeFilter) (*model.Provenance, error) {
+	intent, err := fileDigest(intentFile)
+	if err != nil {
+		return nil, err
+	}
+
+	provenance := &model.Provenance{
+		Liteci:       model.ToolVersion{Version: version, Commit: commit},
+		Intent:       intent,
+		Compositions: []model.FileDigest{},
+		Changes:      changes,
+	}
+
+	used := make(map[string]bool)
+	for _, job := range plan.Jobs {
+		used[job.Composition] = true
+	}
+	names := make([]string, 0, len(used))
+	for name := range used {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		composition, ok := registry.Types[name]
+		if !ok {
+			continue
+		}
+		for _, path := range composition.Files {
+			digest, err := fileDigest(path)
+			if err != nil {
+				return nil, err
+			}
+			provenance.Compositions = append(provenance.Compositions, digest)
+		}
+	}
+
+	if head := git.HeadCommit(); head != "" {
+		provenance.Git = &model.GitRevision{Commit: head, Dirty: git.HasUncommittedChanges()}
+	}
+
+	return provenance, nil
+}
+
+func fileDigest(path string) (model.FileDigest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return model.FileDigest{}, fmt.Errorf("failed to read %s for provenance: %w", path, err)
+	}
+	sum := sha256.Sum256(data)
+	return model.FileDigest{Path: path, SHA256: hex.EncodeToString(sum[:])}, nil
+}
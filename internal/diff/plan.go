@@ -0,0 +1,441 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/sourceplane/liteci/internal/model"
+)
+
+// Kinds of step changes
+const (
+	StepAdded    = "added"
+	StepRemoved  = "removed"
+	StepModified = "modified"
+)
+
+// PlanDiff is the difference between two plans, matching jobs by ID
+type PlanDiff struct {
+	Added     []string  `json:"added"`     // Jobs only in the new plan
+	Removed   []string  `json:"removed"`   // Jobs only in the old plan
+	Modified  []JobDiff `json:"modified"`  // Jobs in both plans that differ
+	Unchanged int       `json:"unchanged"` // Number of identical jobs
+	Edges     EdgeDiff  `json:"edges"`     // dependsOn edges added or removed across the plan
+}
+
+// JobDiff lists what changed in a job present in both plans
+type JobDiff struct {
+	ID     string        `json:"id"`
+	Fields []ValueChange `json:"fields,omitempty"` // path, timeout, retries, labels, conditions, gates...
+	Inputs []ValueChange `json:"inputs,omitempty"` // config
+	Env    []ValueChange `json:"env,omitempty"`
+	Steps  []StepDiff    `json:"steps,omitempty"`
+}
+
+// ValueChange is a value that was added (no Before), removed (no After) or changed
+type ValueChange struct {
+	Key    string      `json:"key"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// StepDiff is a step that was added, removed or modified, matched by name
+type StepDiff struct {
+	Name   string        `json:"name"`
+	Change string        `json:"change"`
+	Run    []string      `json:"run,omitempty"`    // Line diff of the command: "+ ", "- " or "  " prefixed
+	Fields []ValueChange `json:"fields,omitempty"` // timeout, retry, onFailure
+}
+
+// EdgeDiff lists dependency edges that exist in only one of the plans
+type EdgeDiff struct {
+	Added   []Edge `json:"added"`
+	Removed []Edge `json:"removed"`
+}
+
+// Edge is a dependsOn edge: To depends on From
+type Edge struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Condition string `json:"condition,omitempty"`
+}
+
+// Plans compares two plans
+func Plans(oldPlan, newPlan *model.Plan) *PlanDiff {
+	d := &PlanDiff{
+		Added:    []string{},
+		Removed:  []string{},
+		Modified: []JobDiff{},
+		Edges:    EdgeDiff{Added: []Edge{}, Removed: []Edge{}},
+	}
+
+	oldJobs := indexJobs(oldPlan)
+	newJobs := indexJobs(newPlan)
+
+	for _, id := range sortedIDs(newJobs) {
+		if _, ok := oldJobs[id]; !ok {
+			d.Added = append(d.Added, id)
+		}
+	}
+	for _, id := range sortedIDs(oldJobs) {
+		newJob, ok := newJobs[id]
+		if !ok {
+			d.Removed = append(d.Removed, id)
+			continue
+		}
+		if jobDiff := compareJobs(oldJobs[id], newJob); jobDiff != nil {
+			d.Modified = append(d.Modified, *jobDiff)
+		} else {
+			d.Unchanged++
+		}
+	}
+
+	oldEdges := edges(oldJobs)
+	newEdges := edges(newJobs)
+	for _, key := range sortedEdgeKeys(newEdges) {
+		if _, ok := oldEdges[key]; !ok {
+			d.Edges.Added = append(d.Edges.Added, newEdges[key])
+		}
+	}
+	for _, key := range sortedEdgeKeys(oldEdges) {
+		if _, ok := newEdges[key]; !ok {
+			d.Edges.Removed = append(d.Edges.Removed, oldEdges[key])
+		}
+	}
+
+	return d
+}
+
+// HasChanges reports whether the plans differ
+func (d *PlanDiff) HasChanges() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0 || len(d.Modified) > 0 ||
+		len(d.Edges.Added) > 0 || len(d.Edges.Removed) > 0
+}
+
+func compareJobs(oldJob, newJob *model.PlanJob) *JobDiff {
+	d := &JobDiff{ID: oldJob.ID}
+
+	fields := []struct {
+		key           string
+		before, after interface{}
+	}{
+		{"name", oldJob.Name, newJob.Name},
+		{"composition", oldJob.Composition, newJob.Composition},
+		{"jobRegistry", oldJob.JobRegistry, newJob.JobRegistry},
+		{"path", oldJob.Path, newJob.Path},
+		{"timeout", oldJob.Timeout, newJob.Timeout},
+		{"retries", oldJob.Retries, newJob.Retries},
+	}
+	for _, field := range fields {
+		if !sameValue(field.before, field.after) {
+			d.Fields = append(d.Fields, ValueChange{Key: field.key, Before: field.before, After: field.after})
+		}
+	}
+	d.Fields = append(d.Fields, compareMaps("labels.", stringMap(oldJob.Labels), stringMap(newJob.Labels))...)
+	d.Fields = append(d.Fields, compareMaps("conditions.", stringMap(oldJob.Conditions), stringMap(newJob.Conditions))...)
+	if !reflect.DeepEqual(gateList(oldJob.Gates), gateList(newJob.Gates)) {
+		d.Fields = append(d.Fields, valueChange("gates", gateList(oldJob.Gates), gateList(newJob.Gates)))
+	}
+
+	d.Inputs = compareMaps("", oldJob.Config, newJob.Config)
+	d.Env = compareMaps("", oldJob.Env, newJob.Env)
+
+	var order []ValueChange
+	d.Steps, order = compareSteps(oldJob.Steps, newJob.Steps)
+	d.Fields = append(d.Fields, order...)
+
+	if len(d.Fields) == 0 && len(d.Inputs) == 0 && len(d.Env) == 0 && len(d.Steps) == 0 {
+		return nil
+	}
+	return d
+}
+
+// compareSteps matches steps by name (and occurrence, for repeated names)
+// and reports a reordering of the steps both jobs have as a field change
+func compareSteps(oldSteps, newSteps []model.PlanStep) ([]StepDiff, []ValueChange) {
+	oldKeys := stepKeys(oldSteps)
+	newKeys := stepKeys(newSteps)
+	newIndex := make(map[string]int, len(newKeys))
+	for i, key := range newKeys {
+		newIndex[key] = i
+	}
+	oldIndex := make(map[string]int, len(oldKeys))
+	for i, key := range oldKeys {
+		oldIndex[key] = i
+	}
+
+	var diffs []StepDiff
+	var oldCommon, newCommon []string
+	for i, key := range oldKeys {
+		j, ok := newIndex[key]
+		if !ok {
+			diffs = append(diffs, StepDiff{Name: oldSteps[i].Name, Change: StepRemoved, Run: Lines(oldSteps[i].Run, "")})
+			continue
+		}
+		oldCommon = append(oldCommon, oldSteps[i].Name)
+		if stepDiff := compareStep(oldSteps[i], newSteps[j]); stepDiff != nil {
+			diffs = append(diffs, *stepDiff)
+		}
+	}
+	for j, key := range newKeys {
+		if _, ok := oldIndex[key]; ok {
+			newCommon = append(newCommon, newSteps[j].Name)
+			continue
+		}
+		diffs = append(diffs, StepDiff{Name: newSteps[j].Name, Change: StepAdded, Run: Lines("", newSteps[j].Run)})
+	}
+
+	var order []ValueChange
+	if !reflect.DeepEqual(oldCommon, newCommon) {
+		order = append(order, valueChange("steps.order", oldCommon, newCommon))
+	}
+	return diffs, order
+}
+
+func compareStep(oldStep, newStep model.PlanStep) *StepDiff {
+	d := &StepDiff{Name: oldStep.Name, Change: StepModified}
+	if oldStep.Run != newStep.Run {
+		d.Run = Lines(oldStep.Run, newStep.Run)
+	}
+	fields := []struct {
+		key           string
+		before, after interface{}
+	}{
+		{"timeout", oldStep.Timeout, newStep.Timeout},
+		{"retry", oldStep.Retry, newStep.Retry},
+		{"onFailure", oldStep.OnFailure, newStep.OnFailure},
+	}
+	for _, field := range fields {
+		if !sameValue(field.before, field.after) {
+			d.Fields = append(d.Fields, ValueChange{Key: field.key, Before: field.before, After: field.after})
+		}
+	}
+	if len(d.Run) == 0 && len(d.Fields) == 0 {
+		return nil
+	}
+	return d
+}
+
+// stepKeys names each step by its name and, for repeated names, occurrence
+func stepKeys(steps []model.PlanStep) []string {
+	seen := make(map[string]int, len(steps))
+	keys := make([]string, len(steps))
+	for i, step := range steps {
+		seen[step.Name]++
+		keys[i] = fmt.Sprintf("%s#%d", step.Name, seen[step.Name])
+	}
+	return keys
+}
+
+// compareMaps reports keys added, removed or changed between two maps,
+// sorted by key and prefixed with prefix
+func compareMaps(prefix string, before, after map[string]interface{}) []ValueChange {
+	keys := make(map[string]bool)
+	for key := range before {
+		keys[key] = true
+	}
+	for key := range after {
+		keys[key] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	var changes []ValueChange
+	for _, key := range sorted {
+		oldValue, inOld := before[key]
+		newValue, inNew := after[key]
+		switch {
+		case !inOld:
+			changes = append(changes, ValueChange{Key: prefix + key, After: newValue})
+		case !inNew:
+			changes = append(changes, ValueChange{Key: prefix + key, Before: oldValue})
+		case !sameValue(oldValue, newValue):
+			changes = append(changes, ValueChange{Key: prefix + key, Before: oldValue, After: newValue})
+		}
+	}
+	return changes
+}
+
+// sameValue reports whether two decoded plan values are equal, whichever
+// format they were read from: JSON plans hold json.Number, YAML plans int or
+// float64, so 1, 1.0 and json.Number("1") are the same value
+func sameValue(a, b interface{}) bool {
+	return reflect.DeepEqual(normalizeValue(a), normalizeValue(b))
+}
+
+// normalizeValue converts every number in v to int64, or to float64 when it
+// is not a whole number, recursing into maps and lists
+func normalizeValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i
+		}
+		if f, err := val.Float64(); err == nil {
+			return normalizeValue(f)
+		}
+		return val.String()
+	case int:
+		return int64(val)
+	case int64:
+		return val
+	case uint64:
+		if val <= math.MaxInt64 {
+			return int64(val)
+		}
+		return float64(val)
+	case float64:
+		if val == math.Trunc(val) && val >= math.MinInt64 && val < math.MaxInt64 {
+			return int64(val)
+		}
+		return val
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			result[k] = normalizeValue(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(val))
+		for i, item := range val {
+			result[i] = normalizeValue(item)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+// valueChange builds a change, leaving out an empty side so it reads as an
+// addition or removal
+func valueChange(key string, before, after []string) ValueChange {
+	change := ValueChange{Key: key}
+	if len(before) > 0 {
+		change.Before = before
+	}
+	if len(after) > 0 {
+		change.After = after
+	}
+	return change
+}
+
+func stringMap(m map[string]string) map[string]interface{} {
+	result := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
+
+func gateList(gates []model.Gate) []string {
+	list := make([]string, 0, len(gates))
+	for _, gate := range gates {
+		list = append(list, gate.Type+": "+gate.Reason)
+	}
+	return list
+}
+
+func indexJobs(plan *model.Plan) map[string]*model.PlanJob {
+	jobs := make(map[string]*model.PlanJob, len(plan.Jobs))
+	for i := range plan.Jobs {
+		jobs[plan.Jobs[i].ID] = &plan.Jobs[i]
+	}
+	return jobs
+}
+
+func sortedIDs(jobs map[string]*model.PlanJob) []string {
+	ids := make([]string, 0, len(jobs))
+	for id := range jobs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func edges(jobs map[string]*model.PlanJob) map[string]Edge {
+	result := make(map[string]Edge)
+	for _, job := range jobs {
+		for _, dep := range job.DependsOn {
+			result[dep+" → "+job.ID] = Edge{From: dep, To: job.ID, Condition: job.Conditions[dep]}
+		}
+	}
+	return result
+}
+
+func sortedEdgeKeys(edges map[string]Edge) []string {
+	keys := make([]string, 0, len(edges))
+	for key := range edges {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(a, b int) bool {
+		ea, eb := edges[keys[a]], edges[keys[b]]
+		if ea.To != eb.To {
+			return ea.To < eb.To
+		}
+		return ea.From < eb.From
+	})
+	return keys
+}
+
+// Lines returns a line diff of two texts: unchanged lines prefixed with two
+// spaces, removed lines with "- " and added lines with "+ "
+func Lines(before, after string) []string {
+	a := splitLines(before)
+	b := splitLines(after)
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, "  "+a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, "- "+a[i])
+			i++
+		default:
+			lines = append(lines, "+ "+b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, "- "+a[i])
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, "+ "+b[j])
+	}
+	return lines
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimRight(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
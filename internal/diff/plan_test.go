@@ -0,0 +1,82 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/sourceplane/liteci/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+// samePlanJSON and samePlanYAML are one plan written in both formats
+const samePlanJSON = `{
+  "jobs": [
+    {
+      "id": "web@prod.deploy",
+      "retries": 2,
+      "config": {"replicas": 1, "ratio": 0.5, "db": {"port": 5432}, "zones": [1, 2]},
+      "env": {"LEVEL": 3},
+      "steps": [{"name": "deploy", "run": "echo hi", "retry": 1}]
+    }
+  ]
+}`
+
+const samePlanYAML = `
+jobs:
+  - id: web@prod.deploy
+    retries: 2
+    config:
+      replicas: 1
+      ratio: 0.5
+      db:
+        port: 5432
+      zones: [1, 2]
+    env:
+      LEVEL: 3
+    steps:
+      - name: deploy
+        run: echo hi
+        retry: 1
+`
+
+func TestPlansJSONAndYAMLOfSamePlanHaveNoChanges(t *testing.T) {
+	// Decode JSON the way liteci reads plan files, keeping numbers as json.Number
+	var jsonPlan model.Plan
+	decoder := json.NewDecoder(bytes.NewReader([]byte(samePlanJSON)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&jsonPlan); err != nil {
+		t.Fatalf("decode JSON plan: %v", err)
+	}
+
+	var yamlPlan model.Plan
+	if err := yaml.Unmarshal([]byte(samePlanYAML), &yamlPlan); err != nil {
+		t.Fatalf("decode YAML plan: %v", err)
+	}
+
+	for _, d := range []*PlanDiff{Plans(&jsonPlan, &yamlPlan), Plans(&yamlPlan, &jsonPlan)} {
+		if d.HasChanges() {
+			t.Errorf("expected no changes, got %+v", d.Modified)
+		}
+	}
+}
+
+func TestPlansReportsChangedNumber(t *testing.T) {
+	var jsonPlan model.Plan
+	decoder := json.NewDecoder(bytes.NewReader([]byte(samePlanJSON)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&jsonPlan); err != nil {
+		t.Fatalf("decode JSON plan: %v", err)
+	}
+
+	var yamlPlan model.Plan
+	if err := yaml.Unmarshal([]byte(samePlanYAML), &yamlPlan); err != nil {
+		t.Fatalf("decode YAML plan: %v", err)
+	}
+	yamlPlan.Jobs[0].Config["replicas"] = 3
+
+	d := Plans(&jsonPlan, &yamlPlan)
+	if len(d.Modified) != 1 || len(d.Modified[0].Inputs) != 1 || d.Modified[0].Inputs[0].Key != "replicas" {
+		t.Fatalf("expected only replicas to change, got %+v", d.Modified)
+	}
+}
@@ -0,0 +1,99 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteText writes a human-readable report of the diff
+func (d *PlanDiff) WriteText(w io.Writer) error {
+	var sb strings.Builder
+
+	if !d.HasChanges() {
+		sb.WriteString(fmt.Sprintf("No changes (%d jobs)\n", d.Unchanged))
+		_, err := io.WriteString(w, sb.String())
+		return err
+	}
+
+	for _, id := range d.Added {
+		sb.WriteString(fmt.Sprintf("+ %s (added)\n", id))
+	}
+	for _, id := range d.Removed {
+		sb.WriteString(fmt.Sprintf("- %s (removed)\n", id))
+	}
+
+	for _, job := range d.Modified {
+		sb.WriteString(fmt.Sprintf("~ %s\n", job.ID))
+		writeChanges(&sb, "    ", job.Fields)
+		if len(job.Inputs) > 0 {
+			sb.WriteString("    inputs:\n")
+			writeChanges(&sb, "      ", job.Inputs)
+		}
+		if len(job.Env) > 0 {
+			sb.WriteString("    env:\n")
+			writeChanges(&sb, "      ", job.Env)
+		}
+		for _, step := range job.Steps {
+			sb.WriteString(fmt.Sprintf("    step %s (%s):\n", step.Name, step.Change))
+			writeChanges(&sb, "      ", step.Fields)
+			for _, line := range step.Run {
+				sb.WriteString("      " + line + "\n")
+			}
+		}
+	}
+
+	if len(d.Edges.Added) > 0 || len(d.Edges.Removed) > 0 {
+		sb.WriteString("dependencies:\n")
+		for _, edge := range d.Edges.Added {
+			sb.WriteString(fmt.Sprintf("  + %s\n", edge))
+		}
+		for _, edge := range d.Edges.Removed {
+			sb.WriteString(fmt.Sprintf("  - %s\n", edge))
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("\nSummary: %d added, %d removed, %d modified, %d unchanged jobs; %d dependencies added, %d removed\n",
+		len(d.Added), len(d.Removed), len(d.Modified), d.Unchanged, len(d.Edges.Added), len(d.Edges.Removed)))
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// String renders an edge as "job → dependent", with its condition when it is
+// not the default
+func (e Edge) String() string {
+	if e.Condition != "" && e.Condition != "success" {
+		return fmt.Sprintf("%s → %s (%s)", e.From, e.To, e.Condition)
+	}
+	return fmt.Sprintf("%s → %s", e.From, e.To)
+}
+
+func writeChanges(sb *strings.Builder, indent string, changes []ValueChange) {
+	for _, change := range changes {
+		switch {
+		case change.Before == nil:
+			sb.WriteString(fmt.Sprintf("%s+ %s: %s\n", indent, change.Key, formatValue(change.After)))
+		case change.After == nil:
+			sb.WriteString(fmt.Sprintf("%s- %s: %s\n", indent, change.Key, formatValue(change.Before)))
+		default:
+			sb.WriteString(fmt.Sprintf("%s~ %s: %s → %s\n", indent, change.Key, formatValue(change.Before), formatValue(change.After)))
+		}
+	}
+}
+
+// formatValue shows strings as written and everything else as compact JSON
+func formatValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		if s == "" {
+			return `""`
+		}
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
@@ -1,6 +1,8 @@
 package expand
 
 import (
+	"fmt"
+	"path"
 	"regexp"
 	"strings"
 
@@ -11,6 +13,7 @@ import (
 type Expander struct {
 	normalized *model.NormalizedIntent
 	groups     map[string]model.Group
+	envFilter  []string // Environment names or globs to expand; empty expands every environment
 }
 
 // NewExpander creates a new expander
@@ -21,71 +24,129 @@ func NewExpander(normalized *model.NormalizedIntent) *Expander {
 	}
 }
 
+// RestrictEnvironments limits expansion to the environments matching any of
+// the given names or globs. Every pattern must match at least one environment.
+func (e *Expander) RestrictEnvironments(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid environment pattern %q: %w", pattern, err)
+		}
+		matched := false
+		for envName := range e.normalized.Environments {
+			if ok, _ := path.Match(pattern, envName); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("environment %q matches no environment in the intent", pattern)
+		}
+	}
+	e.envFilter = patterns
+	return nil
+}
+
+// environmentSelected reports whether an environment passes the environment filter
+func (e *Expander) environmentSelected(envName string) bool {
+	if len(e.envFilter) == 0 {
+		return true
+	}
+	for _, pattern := range e.envFilter {
+		if ok, _ := path.Match(pattern, envName); ok {
+			return true
+		}
+	}
+	return false
+}
+
 // Expand produces ComponentInstances for each environment × component pair
 func (e *Expander) Expand() (map[string][]*model.ComponentInstance, error) {
 	result := make(map[string][]*model.ComponentInstance)
 
 	for envName, env := range e.normalized.Environments {
+		if !e.environmentSelected(envName) {
+			continue
+		}
+
 		instances := make([]*model.ComponentInstance, 0)
 
 		// Get applicable components for this environment
-		applicableComps := e.getApplicableComponents(env)
+		selections, err := e.getApplicableComponents(envName, env)
+		if err != nil {
+			return nil, err
+		}
 
-		for _, compName := range applicableComps {
-			comp, exists := e.normalized.ComponentIndex[compName]
-			if !exists {
-				continue
-			}
+		for _, selection := range selections {
+			compName := selection.Component
+			comp := e.normalized.ComponentIndex[compName]
 
 			// Skip disabled components
 			if !comp.Enabled {
 				continue
 			}
 
-			// Create instance with merged properties
-			instance := &model.ComponentInstance{
-				ComponentName: compName,
-				Environment:   envName,
-				Type:          comp.Type,
-				Domain:        comp.Domain,
-				Labels:        comp.Labels,
-				Enabled:       comp.Enabled,
-			}
+			instances = append(instances, e.newInstance(comp, envName, env, selection.MatchedBy))
+		}
 
-			// Merge all properties (including path) with template interpolation
-			merged := e.mergeProperties(comp, env, envName, compName)
-			instance.Inputs = merged
+		result[envName] = instances
+	}
 
-			// Extract path from merged properties if it exists
-			if pathVal, exists := merged["path"]; exists {
-				if pathStr, ok := pathVal.(string); ok {
-					instance.Path = pathStr
-					// Remove path from inputs so it's not duplicated
-					delete(merged, "path")
-				}
-			} else {
-				instance.Path = "./"
-			}
+	return result, nil
+}
 
-			// Extract and apply policies (cannot be overridden)
-			instance.Policies = e.resolvePolicies(comp, envName)
+// newInstance expands a component into an environment, merging its properties,
+// policies and dependencies
+func (e *Expander) newInstance(comp model.Component, envName string, env model.Environment, selectedBy []string) *model.ComponentInstance {
+	instance := &model.ComponentInstance{
+		ComponentName: comp.Name,
+		Environment:   envName,
+		Type:          comp.Type,
+		Domain:        comp.Domain,
+		Job:           comp.Job,
+		Jobs:          comp.Jobs,
+		Labels:        comp.Labels,
+		Enabled:       comp.Enabled,
+		SelectedBy:    selectedBy,
+	}
 
-			// Resolve dependencies
-			deps := e.resolveDependencies(comp, envName)
-			instance.DependsOn = deps
+	// Merge all properties (including path) with template interpolation
+	merged := e.mergeProperties(comp, env, envName, comp.Name)
+	instance.Inputs = merged
 
-			instances = append(instances, instance)
+	// Extract path from merged properties if it exists
+	if pathVal, exists := merged["path"]; exists {
+		if pathStr, ok := pathVal.(string); ok {
+			instance.Path = pathStr
+			// Remove path from inputs so it's not duplicated
+			delete(merged, "path")
 		}
-
-		result[envName] = instances
+	} else {
+		instance.Path = "./"
 	}
 
-	return result, nil
+	// Extract and apply policies (cannot be overridden)
+	instance.Policies = e.resolvePolicies(comp, envName)
+
+	// Resolve dependencies
+	instance.DependsOn = e.resolveDependencies(comp, envName)
+
+	return instance
 }
 
-// getApplicableComponents returns components that apply to an environment
-func (e *Expander) getApplicableComponents(env model.Environment) []string {
-	return env.Selectors.Components
+// getApplicableComponents returns the selections of components that apply to an environment
+func (e *Expander) getApplicableComponents(envName string, env model.Environment) ([]Selection, error) {
+	selections, err := SelectComponents(envName, env, e.normalized.ComponentIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	applicable := make([]Selection, 0, len(selections))
+	for _, selection := range selections {
+		if selection.Selected() {
+			applicable = append(applicable, selection)
+		}
+	}
+	return applicable, nil
 }
 
 // mergeProperties applies the merge precedence order with proper override hierarchy
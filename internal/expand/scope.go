@@ -0,0 +1,126 @@
+package expand
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sourceplane/liteci/internal/model"
+)
+
+// ResolveDependencyScopes checks that every dependency of every instance
+// targets a component instance that was expanded: the dependent's own
+// environment for same-environment dependencies, or the named environment
+// for cross-environment ones.
+//
+// With includeMissing, a target that its environment's selectors do not
+// select is expanded into that environment anyway (and its own dependencies
+// are checked in turn). Otherwise, or when the target cannot be planned at
+// all, the error explains why the target is missing.
+//
+// Every resolved target records the instances that depend on it in
+// RequiredBy.
+func (e *Expander) ResolveDependencyScopes(instances map[string][]*model.ComponentInstance, includeMissing bool) ([]*model.ComponentInstance, error) {
+	expanded := make(map[string]*model.ComponentInstance)
+	queue := make([]*model.ComponentInstance, 0)
+	for envName, envInstances := range instances {
+		for _, inst := range envInstances {
+			expanded[inst.ComponentName+"@"+envName] = inst
+			queue = append(queue, inst)
+		}
+	}
+	sort.Slice(queue, func(i, j int) bool {
+		return instanceKey(queue[i]) < instanceKey(queue[j])
+	})
+
+	included := make([]*model.ComponentInstance, 0)
+	var problems []string
+	for len(queue) > 0 {
+		inst := queue[0]
+		queue = queue[1:]
+
+		for _, dep := range inst.DependsOn {
+			target := dep.ComponentName + "@" + dep.Environment
+			if existing, ok := expanded[target]; ok {
+				existing.RequiredBy = append(existing.RequiredBy, instanceKey(inst))
+				continue
+			}
+
+			reason, includable := e.explainMissing(dep)
+			if !includeMissing || !includable {
+				hint := ""
+				if includable {
+					hint = " (use --include-dependencies to plan it anyway)"
+				}
+				problems = append(problems, fmt.Sprintf("%s depends on %s (%s), but %s%s",
+					instanceKey(inst), target, dep.Scope, reason, hint))
+				continue
+			}
+
+			comp := e.normalized.ComponentIndex[dep.ComponentName]
+			env := e.normalized.Environments[dep.Environment]
+			added := e.newInstance(comp, dep.Environment, env, []string{"dependency of " + instanceKey(inst)})
+			added.RequiredBy = []string{instanceKey(inst)}
+			instances[dep.Environment] = append(instances[dep.Environment], added)
+			expanded[target] = added
+			included = append(included, added)
+			queue = append(queue, added)
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("unresolved dependencies:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return included, nil
+}
+
+// explainMissing says why a dependency target was not expanded and whether
+// it could be included on request
+func (e *Expander) explainMissing(dep model.ResolvedDependency) (string, bool) {
+	env, exists := e.normalized.Environments[dep.Environment]
+	if !exists {
+		return fmt.Sprintf("environment %s is not defined", dep.Environment), false
+	}
+	comp, exists := e.normalized.ComponentIndex[dep.ComponentName]
+	if !exists {
+		return fmt.Sprintf("component %s is not defined", dep.ComponentName), false
+	}
+	if !comp.Enabled {
+		return fmt.Sprintf("component %s is disabled", dep.ComponentName), false
+	}
+	if !e.environmentSelected(dep.Environment) {
+		return fmt.Sprintf("environment %s is outside the selected environments (%s)", dep.Environment, strings.Join(e.envFilter, ", ")), true
+	}
+
+	selections, err := SelectComponents(dep.Environment, env, map[string]model.Component{comp.Name: comp})
+	if err != nil {
+		return err.Error(), false
+	}
+	selection := selections[0]
+	if selection.ExcludedBy != "" {
+		return fmt.Sprintf("environment %s excludes it (%s)", dep.Environment, selection.ExcludedBy), true
+	}
+	return fmt.Sprintf("environment %s does not select it (%s)", dep.Environment, describeSelectors(env.Selectors)), true
+}
+
+// describeSelectors summarizes an environment's selectors for error messages
+func describeSelectors(s model.EnvironmentSelectors) string {
+	var parts []string
+	if len(s.Components) > 0 {
+		parts = append(parts, "components: "+strings.Join(s.Components, ", "))
+	}
+	if len(s.Domains) > 0 {
+		parts = append(parts, "domains: "+strings.Join(s.Domains, ", "))
+	}
+	if len(s.Labels) > 0 {
+		parts = append(parts, "labels: "+strings.Join(s.Labels, ", "))
+	}
+	if len(parts) == 0 {
+		return "no selectors"
+	}
+	return strings.Join(parts, "; ")
+}
+
+func instanceKey(inst *model.ComponentInstance) string {
+	return inst.ComponentName + "@" + inst.Environment
+}
@@ -0,0 +1,187 @@
+package expand
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/sourceplane/liteci/internal/model"
+)
+
+// Selection records why a component is or is not part of an environment
+type Selection struct {
+	Component  string
+	MatchedBy  []string // Selectors that include the component, e.g. "domains: platform"
+	ExcludedBy string   // Exclusion that removes it, e.g. "components: !legacy-*"
+}
+
+// Selected reports whether the component is planned in the environment
+func (s Selection) Selected() bool {
+	return len(s.MatchedBy) > 0 && s.ExcludedBy == ""
+}
+
+// Describe explains the selection in one line
+func (s Selection) Describe() string {
+	switch {
+	case s.ExcludedBy != "":
+		return "excluded by " + s.ExcludedBy
+	case len(s.MatchedBy) > 0:
+		return "selected by " + strings.Join(s.MatchedBy, ", ")
+	default:
+		return "not matched by any selector"
+	}
+}
+
+// selector is one entry of an environment selector list
+type selector struct {
+	kind    string // components, domains or labels
+	raw     string // entry as written, including a leading "!"
+	exclude bool
+	matches func(comp model.Component) bool
+}
+
+// SelectComponents evaluates an environment's selectors against every component,
+// returning one Selection per component sorted by name. Components are selected
+// when any entry of selectors.components (name globs such as web-*),
+// selectors.domains (domain globs) or selectors.labels (label selectors such
+// as tier=frontend) matches them, and no entry prefixed with "!" does.
+func SelectComponents(envName string, env model.Environment, components map[string]model.Component) ([]Selection, error) {
+	selectors, err := parseSelectors(env.Selectors)
+	if err != nil {
+		return nil, fmt.Errorf("environment %s: %w", envName, err)
+	}
+
+	names := make([]string, 0, len(components))
+	for name := range components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	selections := make([]Selection, 0, len(names))
+	for _, name := range names {
+		comp := components[name]
+		selection := Selection{Component: name}
+		for _, s := range selectors {
+			if !s.matches(comp) {
+				continue
+			}
+			entry := fmt.Sprintf("%s: %s", s.kind, s.raw)
+			if s.exclude {
+				if selection.ExcludedBy == "" {
+					selection.ExcludedBy = entry
+				}
+				continue
+			}
+			selection.MatchedBy = append(selection.MatchedBy, entry)
+		}
+		selections = append(selections, selection)
+	}
+
+	return selections, nil
+}
+
+func parseSelectors(s model.EnvironmentSelectors) ([]selector, error) {
+	var selectors []selector
+
+	for _, raw := range s.Components {
+		pattern, exclude := strings.CutPrefix(raw, "!")
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid component selector %q: %w", raw, err)
+		}
+		selectors = append(selectors, selector{
+			kind:    "components",
+			raw:     raw,
+			exclude: exclude,
+			matches: func(comp model.Component) bool {
+				matched, _ := path.Match(pattern, comp.Name)
+				return matched
+			},
+		})
+	}
+
+	for _, raw := range s.Domains {
+		pattern, exclude := strings.CutPrefix(raw, "!")
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid domain selector %q: %w", raw, err)
+		}
+		selectors = append(selectors, selector{
+			kind:    "domains",
+			raw:     raw,
+			exclude: exclude,
+			matches: func(comp model.Component) bool {
+				if comp.Domain == "" {
+					return false
+				}
+				matched, _ := path.Match(pattern, comp.Domain)
+				return matched
+			},
+		})
+	}
+
+	for _, raw := range s.Labels {
+		expr, exclude := strings.CutPrefix(raw, "!")
+		requirements, err := parseLabelSelector(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid label selector %q: %w", raw, err)
+		}
+		selectors = append(selectors, selector{
+			kind:    "labels",
+			raw:     raw,
+			exclude: exclude,
+			matches: func(comp model.Component) bool {
+				for _, req := range requirements {
+					if !req.matches(comp.Labels) {
+						return false
+					}
+				}
+				return true
+			},
+		})
+	}
+
+	return selectors, nil
+}
+
+// labelRequirement is one comma-separated term of a label selector:
+// key=value, key!=value or a bare key that must be present
+type labelRequirement struct {
+	key      string
+	value    string
+	operator string // =, != or exists
+}
+
+func parseLabelSelector(expr string) ([]labelRequirement, error) {
+	var requirements []labelRequirement
+	for _, term := range strings.Split(expr, ",") {
+		term = strings.TrimSpace(term)
+		var req labelRequirement
+		switch {
+		case strings.Contains(term, "!="):
+			key, value, _ := strings.Cut(term, "!=")
+			req = labelRequirement{key: strings.TrimSpace(key), value: strings.TrimSpace(value), operator: "!="}
+		case strings.Contains(term, "="):
+			key, value, _ := strings.Cut(term, "=")
+			req = labelRequirement{key: strings.TrimSpace(key), value: strings.TrimSpace(value), operator: "="}
+		default:
+			req = labelRequirement{key: term, operator: "exists"}
+		}
+		if req.key == "" {
+			return nil, fmt.Errorf("empty label key")
+		}
+		requirements = append(requirements, req)
+	}
+	return requirements, nil
+}
+
+func (r labelRequirement) matches(labels map[string]string) bool {
+	value, ok := labels[r.key]
+	switch r.operator {
+	case "=":
+		return ok && value == r.value
+	case "!=":
+		return !ok || value != r.value
+	default:
+		return ok
+	}
+}
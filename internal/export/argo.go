@@ -0,0 +1,247 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sourceplane/liteci/internal/model"
+	"github.com/sourceplane/liteci/internal/runner"
+)
+
+// argoEntrypoint is the name of the DAG template that runs the whole plan
+const argoEntrypoint = "plan"
+
+// ArgoWorkflowExporter renders a plan as an Argo Workflow with a DAG template.
+// Each plan job becomes a script template whose source runs the job's steps
+// in order; the job's flattened inputs become input parameters, exported to
+// the script as LITECI_INPUT_* variables. dependsOn and the dependency
+// conditions become the DAG task's depends expression, timeout becomes
+// activeDeadlineSeconds and retries a retryStrategy. A gated job waits on a
+// suspend task, resumed with `argo resume`. Argo cannot retry or time out a
+// single step of a script, so step retries and timeouts are dropped with a
+// warning.
+type ArgoWorkflowExporter struct {
+	opts Options
+}
+
+// NewArgoWorkflowExporter creates an Argo Workflow exporter
+func NewArgoWorkflowExporter(opts Options) *ArgoWorkflowExporter {
+	if opts.Image == "" {
+		opts.Image = DefaultImage
+	}
+	return &ArgoWorkflowExporter{opts: opts}
+}
+
+type argoWorkflow struct {
+	APIVersion string           `yaml:"apiVersion"`
+	Kind       string           `yaml:"kind"`
+	Metadata   argoMetadata     `yaml:"metadata"`
+	Spec       argoWorkflowSpec `yaml:"spec"`
+}
+
+type argoMetadata struct {
+	GenerateName string `yaml:"generateName"`
+}
+
+type argoWorkflowSpec struct {
+	Entrypoint string         `yaml:"entrypoint"`
+	Templates  []argoTemplate `yaml:"templates"`
+}
+
+type argoTemplate struct {
+	Name                  string             `yaml:"name"`
+	Inputs                *argoInputs        `yaml:"inputs,omitempty"`
+	DAG                   *argoDAG           `yaml:"dag,omitempty"`
+	Suspend               *struct{}          `yaml:"suspend,omitempty"`
+	ActiveDeadlineSeconds int                `yaml:"activeDeadlineSeconds,omitempty"`
+	RetryStrategy         *argoRetryStrategy `yaml:"retryStrategy,omitempty"`
+	Script                *argoScript        `yaml:"script,omitempty"`
+}
+
+type argoInputs struct {
+	Parameters []argoParameter `yaml:"parameters"`
+}
+
+type argoParameter struct {
+	Name  string  `yaml:"name"`
+	Value *string `yaml:"value,omitempty"`
+}
+
+type argoDAG struct {
+	Tasks []argoTask `yaml:"tasks"`
+}
+
+type argoTask struct {
+	Name      string         `yaml:"name"`
+	Template  string         `yaml:"template"`
+	Depends   string         `yaml:"depends,omitempty"`
+	Arguments *argoArguments `yaml:"arguments,omitempty"`
+}
+
+type argoArguments struct {
+	Parameters []argoParameter `yaml:"parameters"`
+}
+
+type argoRetryStrategy struct {
+	Limit int `yaml:"limit"`
+}
+
+type argoScript struct {
+	Image   string    `yaml:"image"`
+	Command []string  `yaml:"command"`
+	Source  string    `yaml:"source"`
+	Env     []nameVal `yaml:"env,omitempty"`
+}
+
+// nameVal is a name/value pair, as used for container environment variables
+// and task parameters
+type nameVal struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
+// Export renders the Workflow YAML, validated against the vendored schema
+func (e *ArgoWorkflowExporter) Export(plan *model.Plan) ([]byte, error) {
+	ids, err := sanitizeIDs(plan.Jobs, dnsLabel)
+	if err != nil {
+		return nil, err
+	}
+	taken := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		taken[id] = true
+	}
+
+	dag := &argoDAG{}
+	templates := []argoTemplate{{Name: argoEntrypoint, DAG: dag}}
+	for _, job := range plan.Jobs {
+		params, err := inputParameters(job, identifier)
+		if err != nil {
+			return nil, fmt.Errorf("job %s: %w", job.ID, err)
+		}
+		template, err := e.jobTemplate(job, ids[job.ID], params)
+		if err != nil {
+			return nil, fmt.Errorf("job %s: %w", job.ID, err)
+		}
+		templates = append(templates, template)
+
+		depends, err := argoDepends(job, ids)
+		if err != nil {
+			return nil, fmt.Errorf("job %s: %w", job.ID, err)
+		}
+
+		if len(job.Gates) > 0 {
+			approve := dnsLabel("approve-" + ids[job.ID])
+			if taken[approve] {
+				return nil, fmt.Errorf("job %s: approval task name %q clashes with a job", job.ID, approve)
+			}
+			taken[approve] = true
+			templates = append(templates, argoTemplate{Name: approve, Suspend: &struct{}{}})
+			dag.Tasks = append(dag.Tasks, argoTask{Name: approve, Template: approve, Depends: depends})
+			depends = approve + ".Succeeded"
+		}
+
+		task := argoTask{Name: ids[job.ID], Template: ids[job.ID], Depends: depends}
+		if template.Inputs != nil {
+			args := &argoArguments{}
+			for _, param := range params {
+				value := param.Value
+				args.Parameters = append(args.Parameters, argoParameter{Name: param.Name, Value: &value})
+			}
+			task.Arguments = args
+		}
+		dag.Tasks = append(dag.Tasks, task)
+	}
+
+	workflow := argoWorkflow{
+		APIVersion: "argoproj.io/v1alpha1",
+		Kind:       "Workflow",
+		Metadata:   argoMetadata{GenerateName: dnsLabel(pipelineName(plan, e.opts)) + "-"},
+		Spec:       argoWorkflowSpec{Entrypoint: argoEntrypoint, Templates: templates},
+	}
+
+	data, err := marshalYAML(workflow)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render workflow: %w", err)
+	}
+	if err := validateManifest(argoWorkflowSchema, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (e *ArgoWorkflowExporter) jobTemplate(job model.PlanJob, name string, params []parameter) (argoTemplate, error) {
+	script := &argoScript{
+		Image:   e.opts.Image,
+		Command: []string{"sh"},
+		Source:  jobScript(job),
+		Env:     builtinEnv(job),
+	}
+
+	template := argoTemplate{Name: name, Script: script}
+
+	if len(params) > 0 {
+		template.Inputs = &argoInputs{}
+		for _, param := range params {
+			template.Inputs.Parameters = append(template.Inputs.Parameters, argoParameter{Name: param.Name})
+			script.Env = append(script.Env, nameVal{
+				Name:  param.Variable,
+				Value: fmt.Sprintf("{{inputs.parameters.%s}}", param.Name),
+			})
+		}
+	}
+
+	if job.Timeout != "" {
+		d, err := time.ParseDuration(job.Timeout)
+		if err != nil || d < 0 {
+			return argoTemplate{}, fmt.Errorf("invalid timeout %q", job.Timeout)
+		}
+		template.ActiveDeadlineSeconds = int(d.Seconds())
+	}
+	if job.Retries > 0 {
+		template.RetryStrategy = &argoRetryStrategy{Limit: job.Retries}
+	}
+
+	for _, step := range job.Steps {
+		if step.Retry > 0 {
+			e.opts.warnf("job %s step %s: retry: %d dropped; Argo cannot retry steps of a script", job.ID, step.Name, step.Retry)
+		}
+		if step.Timeout != "" {
+			e.opts.warnf("job %s step %s: timeout: %s dropped; Argo only has template deadlines", job.ID, step.Name, step.Timeout)
+		}
+	}
+
+	return template, nil
+}
+
+// argoDepends expresses a job's dependencies and their conditions as an Argo
+// depends expression
+func argoDepends(job model.PlanJob, ids map[string]string) (string, error) {
+	terms := make([]string, 0, len(job.DependsOn))
+	for _, dep := range job.DependsOn {
+		id, ok := ids[dep]
+		if !ok {
+			return "", fmt.Errorf("depends on unknown job %s", dep)
+		}
+		switch job.Conditions[dep] {
+		case model.ConditionAlways:
+			terms = append(terms, fmt.Sprintf("(%s.Succeeded || %s.Failed || %s.Errored)", id, id, id))
+		case model.ConditionFailure:
+			terms = append(terms, id+".Failed")
+		default:
+			terms = append(terms, id+".Succeeded")
+		}
+	}
+	return strings.Join(terms, " && "), nil
+}
+
+// builtinEnv returns the built-in LITECI_* variables of a job, without inputs
+func builtinEnv(job model.PlanJob) []nameVal {
+	return []nameVal{
+		{Name: runner.EnvJobID, Value: job.ID},
+		{Name: runner.EnvJobName, Value: job.Name},
+		{Name: runner.EnvComponent, Value: job.Component},
+		{Name: runner.EnvEnvironment, Value: job.Environment},
+		{Name: runner.EnvComposition, Value: job.Composition},
+	}
+}
@@ -0,0 +1,232 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sourceplane/liteci/internal/model"
+	"github.com/sourceplane/liteci/internal/runner"
+	"gopkg.in/yaml.v3"
+)
+
+// Exporter converts a plan into the pipeline definition of another CI system
+type Exporter interface {
+	Export(plan *model.Plan) ([]byte, error)
+}
+
+// Options tune the generated pipeline
+type Options struct {
+	Name   string // Pipeline name (default: plan metadata name)
+	RunsOn string // GitHub Actions runner label (default ubuntu-latest)
+	Image  string // Container image the jobs run in, for targets that run containers
+
+	// Warnings receives a line for every plan feature the target cannot
+	// express and drops from the pipeline; nil discards them
+	Warnings io.Writer
+}
+
+// warnf reports a plan feature dropped from the exported pipeline
+func (o Options) warnf(format string, args ...interface{}) {
+	if o.Warnings == nil {
+		return
+	}
+	fmt.Fprintf(o.Warnings, "⚠ "+format+"\n", args...)
+}
+
+// DefaultImage is the container image jobs run in when Options.Image is empty
+const DefaultImage = "alpine:3"
+
+// Export targets
+const (
+	TargetGitHubActions  = "github-actions"
+	TargetGitLabCI       = "gitlab-ci"
+	TargetArgoWorkflow   = "argo-workflow"
+	TargetTektonPipeline = "tekton-pipeline"
+)
+
+// New returns the exporter for a target
+func New(target string, opts Options) (Exporter, error) {
+	switch target {
+	case TargetGitHubActions:
+		return NewGitHubActionsExporter(opts), nil
+	case TargetGitLabCI:
+		return NewGitLabCIExporter(opts), nil
+	case TargetArgoWorkflow:
+		return NewArgoWorkflowExporter(opts), nil
+	case TargetTektonPipeline:
+		return NewTektonPipelineExporter(opts), nil
+	default:
+		return nil, fmt.Errorf("unknown export target %q (available: %s)", target, strings.Join(Targets(), ", "))
+	}
+}
+
+// Targets lists the supported export targets
+func Targets() []string {
+	targets := []string{TargetGitHubActions, TargetGitLabCI, TargetArgoWorkflow, TargetTektonPipeline}
+	sort.Strings(targets)
+	return targets
+}
+
+// pipelineName returns the configured name or the plan's name
+func pipelineName(plan *model.Plan, opts Options) string {
+	if opts.Name != "" {
+		return opts.Name
+	}
+	if plan.Metadata.Name != "" {
+		return plan.Metadata.Name
+	}
+	return "liteci"
+}
+
+// timeoutMinutes converts a plan timeout such as 15m or 90s to whole minutes,
+// rounding up; an empty timeout is 0
+func timeoutMinutes(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout %q: %w", value, err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("invalid timeout %q: must not be negative", value)
+	}
+	return int(math.Ceil(d.Minutes())), nil
+}
+
+// sanitizeIDs maps every job ID to an identifier accepted by the target,
+// failing if two jobs collapse to the same identifier
+func sanitizeIDs(jobs []model.PlanJob, sanitize func(string) string) (map[string]string, error) {
+	ids := make(map[string]string, len(jobs))
+	owners := make(map[string]string, len(jobs))
+	for _, job := range jobs {
+		id := sanitize(job.ID)
+		if other, taken := owners[id]; taken {
+			return nil, fmt.Errorf("jobs %s and %s both export as %q", other, job.ID, id)
+		}
+		owners[id] = job.ID
+		ids[job.ID] = id
+	}
+	return ids, nil
+}
+
+// marshalYAML renders v as YAML with the two-space indentation CI systems'
+// own examples use
+func marshalYAML(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// parameter is a flattened job input exported as a string parameter
+type parameter struct {
+	Name     string // Parameter name accepted by the target
+	Variable string // LITECI_INPUT_* variable the parameter is exported as
+	Value    string
+}
+
+// inputParameters turns a job's flattened inputs (Config overlaid by Env, as
+// liteci run exports them) into parameters sorted by variable, failing if two
+// variables sanitize to the same parameter name
+func inputParameters(job model.PlanJob, sanitize func(string) string) ([]parameter, error) {
+	vars, err := runner.JobEnvironment(job)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		if strings.HasPrefix(name, runner.EnvInputPrefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	params := make([]parameter, 0, len(names))
+	owners := make(map[string]string, len(names))
+	for _, name := range names {
+		param := sanitize(strings.TrimPrefix(name, runner.EnvInputPrefix))
+		if other, taken := owners[param]; taken {
+			return nil, fmt.Errorf("inputs %s and %s both export as parameter %q", other, name, param)
+		}
+		owners[param] = name
+		params = append(params, parameter{Name: param, Variable: name, Value: vars[name]})
+	}
+	return params, nil
+}
+
+// stepScript returns the shell commands of a step, tolerating its failure when
+// it is marked onFailure: continue
+func stepScript(step model.PlanStep) string {
+	run := strings.TrimRight(step.Run, "\n")
+	if step.OnFailure == "continue" {
+		return fmt.Sprintf("( %s ) || echo %s", run, shellQuote("⚠ Step "+step.Name+" failed, continuing (onFailure: continue)"))
+	}
+	return run
+}
+
+// jobScript returns a single shell script running every step of a job in its
+// working directory, exporting LITECI_STEP before each step
+func jobScript(job model.PlanJob) string {
+	lines := []string{"set -e"}
+	if job.Path != "" && job.Path != "./" && job.Path != "." {
+		lines = append(lines, "cd "+shellQuote(job.Path))
+	}
+	for _, step := range job.Steps {
+		lines = append(lines, fmt.Sprintf("export %s=%s", runner.EnvStep, shellQuote(step.Name)), stepScript(step))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// identifier converts s to letters, digits, - and _, starting with a letter
+// or _ (web-app@production.deploy → web-app_production_deploy), as GitHub job
+// IDs and Tekton parameter names require
+func identifier(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			sb.WriteRune(r)
+		default:
+			sb.WriteByte('_')
+		}
+	}
+	result := sb.String()
+	if result == "" || !(result[0] == '_' || (result[0] >= 'a' && result[0] <= 'z') || (result[0] >= 'A' && result[0] <= 'Z')) {
+		result = "_" + result
+	}
+	return result
+}
+
+// dnsLabel converts s to a lowercase RFC 1123 label of at most 63 characters
+// (web-app@production.deploy → web-app-production-deploy)
+func dnsLabel(s string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteByte('-')
+		}
+	}
+	label := strings.Trim(sb.String(), "-")
+	if len(label) > 63 {
+		label = strings.TrimRight(label[:63], "-")
+	}
+	if label == "" {
+		label = "x"
+	}
+	return label
+}
@@ -0,0 +1,197 @@
+package export
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sourceplane/liteci/internal/model"
+	"github.com/sourceplane/liteci/internal/runner"
+)
+
+// DefaultGitHubRunsOn is the runner label used when Options.RunsOn is empty
+const DefaultGitHubRunsOn = "ubuntu-latest"
+
+// GitHubActionsExporter renders a plan as a GitHub Actions workflow.
+// Each plan job becomes a workflow job: dependsOn becomes needs, timeout
+// becomes timeout-minutes, path becomes the working directory and the job's
+// inputs are exported with the same LITECI_* variables liteci run sets.
+// Dependency conditions become an if: expression on the needs results, and
+// gated jobs run in a GitHub environment named after the plan environment so
+// its protection rules can require an approval. Job and step retries have no
+// GitHub equivalent; they are dropped with a warning. Env values, step names
+// and scripts are escaped so plan inputs containing ${{ are not evaluated as
+// expressions.
+type GitHubActionsExporter struct {
+	opts Options
+}
+
+// NewGitHubActionsExporter creates a GitHub Actions exporter
+func NewGitHubActionsExporter(opts Options) *GitHubActionsExporter {
+	if opts.RunsOn == "" {
+		opts.RunsOn = DefaultGitHubRunsOn
+	}
+	return &GitHubActionsExporter{opts: opts}
+}
+
+type githubWorkflow struct {
+	Name string                 `yaml:"name"`
+	On   map[string]interface{} `yaml:"on"`
+	Jobs map[string]githubJob   `yaml:"jobs"`
+}
+
+type githubJob struct {
+	Name           string            `yaml:"name"`
+	RunsOn         string            `yaml:"runs-on"`
+	Needs          []string          `yaml:"needs,omitempty"`
+	If             string            `yaml:"if,omitempty"`
+	Environment    string            `yaml:"environment,omitempty"`
+	TimeoutMinutes int               `yaml:"timeout-minutes,omitempty"`
+	Env            map[string]string `yaml:"env,omitempty"`
+	Defaults       *githubDefaults   `yaml:"defaults,omitempty"`
+	Steps          []githubStep      `yaml:"steps"`
+}
+
+type githubDefaults struct {
+	Run githubRunDefaults `yaml:"run"`
+}
+
+type githubRunDefaults struct {
+	WorkingDirectory string `yaml:"working-directory"`
+}
+
+type githubStep struct {
+	Name            string            `yaml:"name,omitempty"`
+	Uses            string            `yaml:"uses,omitempty"`
+	Run             string            `yaml:"run,omitempty"`
+	TimeoutMinutes  int               `yaml:"timeout-minutes,omitempty"`
+	ContinueOnError bool              `yaml:"continue-on-error,omitempty"`
+	Env             map[string]string `yaml:"env,omitempty"`
+}
+
+// Export renders the workflow YAML
+func (e *GitHubActionsExporter) Export(plan *model.Plan) ([]byte, error) {
+	ids, err := sanitizeIDs(plan.Jobs, identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	workflow := githubWorkflow{
+		Name: pipelineName(plan, e.opts),
+		On:   map[string]interface{}{"workflow_dispatch": map[string]interface{}{}},
+		Jobs: make(map[string]githubJob, len(plan.Jobs)),
+	}
+
+	for _, job := range plan.Jobs {
+		timeout, err := timeoutMinutes(job.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("job %s: %w", job.ID, err)
+		}
+
+		env, err := runner.JobEnvironment(job)
+		if err != nil {
+			return nil, fmt.Errorf("job %s: %w", job.ID, err)
+		}
+
+		ghJob := githubJob{
+			Name:           job.ID,
+			RunsOn:         e.opts.RunsOn,
+			TimeoutMinutes: timeout,
+			Env:            githubEscapeEnv(env),
+			Steps:          []githubStep{{Uses: "actions/checkout@v4"}},
+		}
+		if job.Path != "" && job.Path != "./" && job.Path != "." {
+			ghJob.Defaults = &githubDefaults{Run: githubRunDefaults{WorkingDirectory: job.Path}}
+		}
+		if len(job.Gates) > 0 {
+			ghJob.Environment = job.Environment
+		}
+
+		for _, dep := range job.DependsOn {
+			needID, ok := ids[dep]
+			if !ok {
+				return nil, fmt.Errorf("job %s depends on unknown job %s", job.ID, dep)
+			}
+			ghJob.Needs = append(ghJob.Needs, needID)
+		}
+		ghJob.If = githubCondition(job, ids)
+		if job.Retries > 0 {
+			e.opts.warnf("job %s: retries: %d dropped; GitHub Actions cannot retry jobs", job.ID, job.Retries)
+		}
+
+		for _, step := range job.Steps {
+			stepTimeout, err := timeoutMinutes(step.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("job %s step %s: %w", job.ID, step.Name, err)
+			}
+			if step.Retry > 0 {
+				e.opts.warnf("job %s step %s: retry: %d dropped; GitHub Actions cannot retry steps", job.ID, step.Name, step.Retry)
+			}
+			switch step.OnFailure {
+			case "", runner.OnFailureStop, runner.OnFailureContinue:
+			default:
+				e.opts.warnf("job %s step %s: onFailure: %s dropped; only %s and %s are exported", job.ID, step.Name, step.OnFailure, runner.OnFailureStop, runner.OnFailureContinue)
+			}
+			ghJob.Steps = append(ghJob.Steps, githubStep{
+				Name:            githubEscape(step.Name),
+				Run:             githubEscape(step.Run),
+				TimeoutMinutes:  stepTimeout,
+				ContinueOnError: step.OnFailure == runner.OnFailureContinue,
+				Env:             githubEscapeEnv(map[string]string{runner.EnvStep: step.Name}),
+			})
+		}
+
+		workflow.Jobs[ids[job.ID]] = ghJob
+	}
+
+	data, err := marshalYAML(workflow)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render workflow: %w", err)
+	}
+	return data, nil
+}
+
+// githubCondition expresses non-default dependency conditions as an if:
+// expression. Jobs whose dependencies all use the success condition need none,
+// since GitHub only runs a job once all its needs succeeded.
+func githubCondition(job model.PlanJob, ids map[string]string) string {
+	conditional := false
+	for _, dep := range job.DependsOn {
+		if condition := job.Conditions[dep]; condition != "" && condition != model.ConditionSuccess {
+			conditional = true
+		}
+	}
+	if !conditional {
+		return ""
+	}
+
+	terms := []string{"always()"}
+	deps := append([]string(nil), job.DependsOn...)
+	sort.Strings(deps)
+	for _, dep := range deps {
+		switch job.Conditions[dep] {
+		case model.ConditionAlways:
+			// Any result will do, as long as the job finished
+		case model.ConditionFailure:
+			terms = append(terms, fmt.Sprintf("needs.%s.result == 'failure'", ids[dep]))
+		default:
+			terms = append(terms, fmt.Sprintf("needs.%s.result == 'success'", ids[dep]))
+		}
+	}
+	return "${{ " + strings.Join(terms, " && ") + " }}"
+}
+
+// githubEscape keeps GitHub from evaluating ${{ in plan values as an
+// expression: the expression ${{ '${{' }} renders a literal ${{
+func githubEscape(s string) string {
+	return strings.ReplaceAll(s, "${{", "${{ '${{' }}")
+}
+
+// githubEscapeEnv escapes every value of env with githubEscape
+func githubEscapeEnv(env map[string]string) map[string]string {
+	escaped := make(map[string]string, len(env))
+	for name, value := range env {
+		escaped[name] = githubEscape(value)
+	}
+	return escaped
+}
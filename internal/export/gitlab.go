@@ -0,0 +1,299 @@
+package export
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sourceplane/liteci/internal/model"
+	"github.com/sourceplane/liteci/internal/runner"
+	"gopkg.in/yaml.v3"
+)
+
+// gitlabMaxRetry is the highest retry count GitLab accepts
+const gitlabMaxRetry = 2
+
+// gitlabDefaultStage holds jobs that have no environment; it is GitLab's own default stage
+const gitlabDefaultStage = "test"
+
+// GitLabCIExporter renders a plan as a .gitlab-ci.yml pipeline.
+// Environments become stages, ordered so that cross-environment dependencies
+// point to earlier stages, and dependsOn becomes needs so jobs run as a DAG.
+// Retries become retry (capped at GitLab's maximum of 2), timeout becomes
+// timeout and labels become runner tags (key=value). GitLab conditions apply
+// to the whole job rather than to each need: a job with a failure dependency
+// runs when: on_failure, one with an always dependency runs when: always.
+// Gated jobs are manual jobs in a GitLab environment named after the plan
+// environment. Clamped retries, mixed dependency conditions collapsed into one
+// when, and step retries and timeouts (which GitLab cannot express) are
+// reported as warnings.
+type GitLabCIExporter struct {
+	opts Options
+}
+
+// NewGitLabCIExporter creates a GitLab CI exporter
+func NewGitLabCIExporter(opts Options) *GitLabCIExporter {
+	return &GitLabCIExporter{opts: opts}
+}
+
+type gitlabJob struct {
+	Stage        string            `yaml:"stage"`
+	Tags         []string          `yaml:"tags,omitempty"`
+	Needs        []string          `yaml:"needs"`
+	When         string            `yaml:"when,omitempty"`
+	AllowFailure *bool             `yaml:"allow_failure,omitempty"`
+	Environment  string            `yaml:"environment,omitempty"`
+	Retry        int               `yaml:"retry,omitempty"`
+	Timeout      string            `yaml:"timeout,omitempty"`
+	Variables    map[string]string `yaml:"variables,omitempty"`
+	Script       []string          `yaml:"script"`
+}
+
+// gitlabReserved are top-level keywords that cannot be used as job names
+var gitlabReserved = map[string]bool{
+	"default": true, "include": true, "stages": true, "variables": true,
+	"workflow": true, "image": true, "services": true, "cache": true,
+	"before_script": true, "after_script": true, "pages": true,
+}
+
+// Export renders the pipeline YAML
+func (e *GitLabCIExporter) Export(plan *model.Plan) ([]byte, error) {
+	ids, err := sanitizeIDs(plan.Jobs, gitlabJobName)
+	if err != nil {
+		return nil, err
+	}
+
+	stages, err := gitlabStages(plan.Jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	if err := addYAMLField(doc, "stages", stages); err != nil {
+		return nil, err
+	}
+	if e.opts.Image != "" {
+		if err := addYAMLField(doc, "default", map[string]string{"image": e.opts.Image}); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, job := range plan.Jobs {
+		glJob, err := e.exportJob(job, ids)
+		if err != nil {
+			return nil, fmt.Errorf("job %s: %w", job.ID, err)
+		}
+		if err := addYAMLField(doc, ids[job.ID], glJob); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := marshalYAML(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render pipeline: %w", err)
+	}
+	return data, nil
+}
+
+func (e *GitLabCIExporter) exportJob(job model.PlanJob, ids map[string]string) (gitlabJob, error) {
+	variables, err := runner.JobEnvironment(job)
+	if err != nil {
+		return gitlabJob{}, err
+	}
+
+	glJob := gitlabJob{
+		Stage:     gitlabStage(job),
+		Needs:     make([]string, 0, len(job.DependsOn)),
+		Variables: variables,
+	}
+
+	for _, dep := range job.DependsOn {
+		needID, ok := ids[dep]
+		if !ok {
+			return gitlabJob{}, fmt.Errorf("depends on unknown job %s", dep)
+		}
+		glJob.Needs = append(glJob.Needs, needID)
+	}
+
+	for _, key := range sortedLabelKeys(job.Labels) {
+		glJob.Tags = append(glJob.Tags, key+"="+job.Labels[key])
+	}
+
+	glJob.Retry = job.Retries
+	if glJob.Retry > gitlabMaxRetry {
+		e.opts.warnf("job %s: retries: %d capped at %d, GitLab's maximum", job.ID, job.Retries, gitlabMaxRetry)
+		glJob.Retry = gitlabMaxRetry
+	}
+
+	minutes, err := timeoutMinutes(job.Timeout)
+	if err != nil {
+		return gitlabJob{}, err
+	}
+	if minutes > 0 {
+		glJob.Timeout = fmt.Sprintf("%dm", minutes)
+	}
+
+	for _, condition := range job.Conditions {
+		switch {
+		case condition == model.ConditionFailure:
+			glJob.When = "on_failure"
+		case condition == model.ConditionAlways && glJob.When == "":
+			glJob.When = "always"
+		}
+	}
+	if mixed := gitlabMixedConditions(job); mixed != "" {
+		e.opts.warnf("job %s: dependency conditions (%s) collapsed into when: %s for the whole job", job.ID, mixed, glJob.When)
+	}
+
+	if len(job.Gates) > 0 {
+		if glJob.When != "" {
+			e.opts.warnf("job %s: when: %s replaced by when: manual for its approval gate", job.ID, glJob.When)
+		}
+		blocking := false
+		glJob.When = "manual"
+		glJob.AllowFailure = &blocking
+		glJob.Environment = job.Environment
+	}
+
+	if job.Path != "" && job.Path != "./" && job.Path != "." {
+		glJob.Script = append(glJob.Script, "cd "+shellQuote(job.Path))
+	}
+	for _, step := range job.Steps {
+		if step.Retry > 0 {
+			e.opts.warnf("job %s step %s: retry: %d dropped; GitLab cannot retry steps", job.ID, step.Name, step.Retry)
+		}
+		if step.Timeout != "" {
+			e.opts.warnf("job %s step %s: timeout: %s dropped; GitLab only has job timeouts", job.ID, step.Name, step.Timeout)
+		}
+		glJob.Script = append(glJob.Script, fmt.Sprintf("export %s=%s", runner.EnvStep, shellQuote(step.Name)), stepScript(step))
+	}
+
+	return glJob, nil
+}
+
+// gitlabMixedConditions lists a job's dependencies with their conditions when
+// they do not all share one, e.g. "a: success, b: failure"; otherwise ""
+func gitlabMixedConditions(job model.PlanJob) string {
+	deps := append([]string(nil), job.DependsOn...)
+	sort.Strings(deps)
+
+	seen := make(map[string]bool)
+	pairs := make([]string, 0, len(deps))
+	for _, dep := range deps {
+		condition := job.Conditions[dep]
+		if condition == "" {
+			condition = model.ConditionSuccess
+		}
+		seen[condition] = true
+		pairs = append(pairs, dep+": "+condition)
+	}
+	if len(seen) < 2 {
+		return ""
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// gitlabStages orders the plan's environments so every job's needs are in the
+// same or an earlier stage. Ties keep the order environments first appear in
+// the plan.
+func gitlabStages(jobs []model.PlanJob) ([]string, error) {
+	envOf := make(map[string]string, len(jobs))
+	position := make(map[string]int)
+	for _, job := range jobs {
+		envOf[job.ID] = gitlabStage(job)
+		if _, seen := position[envOf[job.ID]]; !seen {
+			position[envOf[job.ID]] = len(position)
+		}
+	}
+
+	before := make(map[string]map[string]bool) // env -> envs that must precede it
+	for _, job := range jobs {
+		for _, dep := range job.DependsOn {
+			depEnv, ok := envOf[dep]
+			env := envOf[job.ID]
+			if !ok || depEnv == env {
+				continue
+			}
+			if before[env] == nil {
+				before[env] = make(map[string]bool)
+			}
+			before[env][depEnv] = true
+		}
+	}
+
+	remaining := make([]string, 0, len(position))
+	for env := range position {
+		remaining = append(remaining, env)
+	}
+	sort.Slice(remaining, func(i, j int) bool {
+		return position[remaining[i]] < position[remaining[j]]
+	})
+
+	stages := make([]string, 0, len(remaining))
+	placed := make(map[string]bool)
+	for len(remaining) > 0 {
+		next := -1
+		for i, env := range remaining {
+			ready := true
+			for required := range before[env] {
+				if !placed[required] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			return nil, fmt.Errorf("environments %s depend on each other, so they cannot be ordered as GitLab stages", strings.Join(remaining, ", "))
+		}
+		placed[remaining[next]] = true
+		stages = append(stages, remaining[next])
+		remaining = append(remaining[:next], remaining[next+1:]...)
+	}
+
+	return stages, nil
+}
+
+// gitlabStage returns the stage of a job: its environment
+func gitlabStage(job model.PlanJob) string {
+	if job.Environment == "" {
+		return gitlabDefaultStage
+	}
+	return job.Environment
+}
+
+// gitlabJobName keeps plan job IDs as GitLab job names unless they would be
+// hidden (leading ".") or clash with a top-level keyword
+func gitlabJobName(id string) string {
+	if strings.HasPrefix(id, ".") || gitlabReserved[id] {
+		return "job-" + id
+	}
+	return id
+}
+
+func sortedLabelKeys(labels map[string]string) []string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
+
+// addYAMLField appends key: value to a mapping node, keeping insertion order
+func addYAMLField(mapping *yaml.Node, key string, value interface{}) error {
+	var valueNode yaml.Node
+	if err := valueNode.Encode(value); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, &valueNode)
+	return nil
+}
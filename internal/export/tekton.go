@@ -0,0 +1,217 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sourceplane/liteci/internal/model"
+	"github.com/sourceplane/liteci/internal/runner"
+)
+
+// TektonPipelineExporter renders a plan as a Tekton Pipeline with embedded
+// task specs. Each plan job becomes a pipeline task whose steps are the job's
+// steps as scripts; the job's flattened inputs become string params, exported
+// to the steps as LITECI_INPUT_* variables. dependsOn becomes runAfter,
+// timeout and retries carry over. Tekton only runs tasks after their
+// dependencies succeed, so a job with a failure or always dependency becomes a finally
+// task guarded by a when expression on the dependency's status. Tekton cannot
+// pause for an approval or retry a single step; gates and step retries are
+// dropped with a warning, so gated jobs run without waiting.
+type TektonPipelineExporter struct {
+	opts Options
+}
+
+// NewTektonPipelineExporter creates a Tekton Pipeline exporter
+func NewTektonPipelineExporter(opts Options) *TektonPipelineExporter {
+	if opts.Image == "" {
+		opts.Image = DefaultImage
+	}
+	return &TektonPipelineExporter{opts: opts}
+}
+
+type tektonPipeline struct {
+	APIVersion string             `yaml:"apiVersion"`
+	Kind       string             `yaml:"kind"`
+	Metadata   tektonMetadata     `yaml:"metadata"`
+	Spec       tektonPipelineSpec `yaml:"spec"`
+}
+
+type tektonMetadata struct {
+	Name string `yaml:"name"`
+}
+
+type tektonPipelineSpec struct {
+	Description string       `yaml:"description,omitempty"`
+	Tasks       []tektonTask `yaml:"tasks"`
+	Finally     []tektonTask `yaml:"finally,omitempty"`
+}
+
+type tektonTask struct {
+	Name     string         `yaml:"name"`
+	RunAfter []string       `yaml:"runAfter,omitempty"`
+	When     []tektonWhen   `yaml:"when,omitempty"`
+	Retries  int            `yaml:"retries,omitempty"`
+	Timeout  string         `yaml:"timeout,omitempty"`
+	Params   []nameVal      `yaml:"params,omitempty"`
+	TaskSpec tektonTaskSpec `yaml:"taskSpec"`
+}
+
+type tektonWhen struct {
+	Input    string   `yaml:"input"`
+	Operator string   `yaml:"operator"`
+	Values   []string `yaml:"values"`
+}
+
+type tektonTaskSpec struct {
+	Params []tektonParamSpec `yaml:"params,omitempty"`
+	Steps  []tektonStep      `yaml:"steps"`
+}
+
+type tektonParamSpec struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+}
+
+type tektonStep struct {
+	Name    string    `yaml:"name"`
+	Image   string    `yaml:"image"`
+	Script  string    `yaml:"script"`
+	Env     []nameVal `yaml:"env,omitempty"`
+	Timeout string    `yaml:"timeout,omitempty"`
+	OnError string    `yaml:"onError,omitempty"`
+}
+
+// Export renders the Pipeline YAML, validated against the vendored schema
+func (e *TektonPipelineExporter) Export(plan *model.Plan) ([]byte, error) {
+	ids, err := sanitizeIDs(plan.Jobs, dnsLabel)
+	if err != nil {
+		return nil, err
+	}
+	if len(plan.Jobs) == 0 {
+		return nil, fmt.Errorf("plan has no jobs; a Tekton pipeline needs at least one task")
+	}
+
+	// Jobs that run whatever the outcome of a dependency must be finally tasks
+	final := make(map[string]bool)
+	for _, job := range plan.Jobs {
+		for _, gate := range job.Gates {
+			e.opts.warnf("job %s: %s gate (%s) dropped; Tekton pipelines cannot wait for an approval", job.ID, gate.Type, gate.Reason)
+		}
+		for _, dep := range job.DependsOn {
+			if cond := job.Conditions[dep]; cond == model.ConditionFailure || cond == model.ConditionAlways {
+				final[job.ID] = true
+			}
+		}
+	}
+
+	spec := tektonPipelineSpec{Description: plan.Metadata.Description}
+	for _, job := range plan.Jobs {
+		task, err := e.task(job, ids, final)
+		if err != nil {
+			return nil, fmt.Errorf("job %s: %w", job.ID, err)
+		}
+		if final[job.ID] {
+			spec.Finally = append(spec.Finally, task)
+		} else {
+			spec.Tasks = append(spec.Tasks, task)
+		}
+	}
+	if len(spec.Tasks) == 0 {
+		return nil, fmt.Errorf("every job depends on a failure or always condition; a Tekton pipeline needs at least one regular task")
+	}
+
+	pipeline := tektonPipeline{
+		APIVersion: "tekton.dev/v1",
+		Kind:       "Pipeline",
+		Metadata:   tektonMetadata{Name: dnsLabel(pipelineName(plan, e.opts))},
+		Spec:       spec,
+	}
+
+	data, err := marshalYAML(pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render pipeline: %w", err)
+	}
+	if err := validateManifest(tektonPipelineSchema, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (e *TektonPipelineExporter) task(job model.PlanJob, ids map[string]string, final map[string]bool) (tektonTask, error) {
+	task := tektonTask{
+		Name:    ids[job.ID],
+		Retries: job.Retries,
+		Timeout: job.Timeout,
+	}
+
+	for _, dep := range job.DependsOn {
+		id, ok := ids[dep]
+		if !ok {
+			return tektonTask{}, fmt.Errorf("depends on unknown job %s", dep)
+		}
+		if final[dep] {
+			return tektonTask{}, fmt.Errorf("depends on %s, which runs as a Tekton finally task", dep)
+		}
+		if !final[job.ID] {
+			task.RunAfter = append(task.RunAfter, id)
+			continue
+		}
+		// finally tasks cannot use runAfter; they wait for every regular task
+		// and check the dependency's outcome instead
+		switch job.Conditions[dep] {
+		case model.ConditionAlways:
+		case model.ConditionFailure:
+			task.When = append(task.When, tektonWhen{Input: fmt.Sprintf("$(tasks.%s.status)", id), Operator: "in", Values: []string{"Failed"}})
+		default:
+			task.When = append(task.When, tektonWhen{Input: fmt.Sprintf("$(tasks.%s.status)", id), Operator: "in", Values: []string{"Succeeded"}})
+		}
+	}
+
+	params, err := inputParameters(job, identifier)
+	if err != nil {
+		return tektonTask{}, err
+	}
+	env := builtinEnv(job)
+	for _, param := range params {
+		task.Params = append(task.Params, nameVal{Name: param.Name, Value: param.Value})
+		task.TaskSpec.Params = append(task.TaskSpec.Params, tektonParamSpec{Name: param.Name, Type: "string"})
+		env = append(env, nameVal{Name: param.Variable, Value: fmt.Sprintf("$(params.%s)", param.Name)})
+	}
+
+	stepNames := make(map[string]bool, len(job.Steps))
+	for i, step := range job.Steps {
+		name := dnsLabel(step.Name)
+		if stepNames[name] {
+			name = dnsLabel(fmt.Sprintf("%s-%d", name, i+1))
+		}
+		stepNames[name] = true
+
+		if step.Retry > 0 {
+			e.opts.warnf("job %s step %s: retry: %d dropped; Tekton cannot retry steps", job.ID, step.Name, step.Retry)
+		}
+
+		lines := []string{"#!/bin/sh", "set -e"}
+		if job.Path != "" && job.Path != "./" && job.Path != "." {
+			lines = append(lines, "cd "+shellQuote(job.Path))
+		}
+		lines = append(lines, strings.TrimRight(step.Run, "\n"))
+
+		stepEnv := append(append([]nameVal{}, env...), nameVal{Name: runner.EnvStep, Value: step.Name})
+		tStep := tektonStep{
+			Name:    name,
+			Image:   e.opts.Image,
+			Script:  strings.Join(lines, "\n") + "\n",
+			Env:     stepEnv,
+			Timeout: step.Timeout,
+		}
+		if step.OnFailure == "continue" {
+			tStep.OnError = "continue"
+		}
+		task.TaskSpec.Steps = append(task.TaskSpec.Steps, tStep)
+	}
+	if len(task.TaskSpec.Steps) == 0 {
+		return tektonTask{}, fmt.Errorf("job has no steps")
+	}
+
+	return task, nil
+}
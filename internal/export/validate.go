@@ -0,0 +1,71 @@
+package export
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// Vendored subsets of the CRD schemas of the Kubernetes targets, so exported
+// manifests are checked without a cluster or network access
+//
+//go:embed schemas/*.schema.yaml
+var schemaFiles embed.FS
+
+const (
+	argoWorkflowSchema   = "schemas/argo-workflow.schema.yaml"
+	tektonPipelineSchema = "schemas/tekton-pipeline.schema.yaml"
+)
+
+// validateManifest checks a rendered YAML manifest against a vendored schema
+func validateManifest(schemaPath string, manifest []byte) error {
+	schema, err := compileSchema(schemaPath)
+	if err != nil {
+		return err
+	}
+
+	var doc interface{}
+	if err := yaml.Unmarshal(manifest, &doc); err != nil {
+		return fmt.Errorf("failed to parse generated manifest: %w", err)
+	}
+	// Round-trip through JSON so the validator sees JSON types
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to encode generated manifest: %w", err)
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to encode generated manifest: %w", err)
+	}
+
+	if err := schema.Validate(doc); err != nil {
+		return fmt.Errorf("generated manifest does not match %s: %w", strings.TrimSuffix(strings.TrimPrefix(schemaPath, "schemas/"), ".schema.yaml"), err)
+	}
+	return nil
+}
+
+// compileSchema loads and compiles an embedded YAML schema
+func compileSchema(path string) (*jsonschema.Schema, error) {
+	data, err := schemaFiles.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema %s: %w", path, err)
+	}
+
+	var schemaData interface{}
+	if err := yaml.Unmarshal(data, &schemaData); err != nil {
+		return nil, fmt.Errorf("failed to parse schema %s: %w", path, err)
+	}
+	jsonData, err := json.Marshal(schemaData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schema %s: %w", path, err)
+	}
+
+	schema, err := jsonschema.CompileString(path, string(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile schema %s: %w", path, err)
+	}
+	return schema, nil
+}
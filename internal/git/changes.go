@@ -150,6 +150,18 @@ func runGitOutput(args ...string) string {
 	return string(output)
 }
 
+// HeadCommit returns the commit checked out in the working directory, or ""
+// outside a git repository
+func HeadCommit() string {
+	return strings.TrimSpace(runGitOutput("rev-parse", "HEAD"))
+}
+
+// HasUncommittedChanges reports whether tracked files in the working
+// directory differ from HEAD
+func HasUncommittedChanges() bool {
+	return len(parseGitOutput("status", "--porcelain", "--untracked-files=no")) > 0
+}
+
 func normalizeFiles(files []string) []string {
 	set := make(map[string]struct{}, len(files))
 	for _, file := range files {
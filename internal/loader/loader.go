@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/santhosh-tekuri/jsonschema/v5"
@@ -68,6 +69,7 @@ type Composition struct {
 	Bindings        *model.JobBinding // Optional job binding declaration
 	JobRegistryName string
 	JobRegistryDesc string
+	Files           []string // job.yaml, schema.yaml and binding.yaml paths the composition was loaded from
 }
 
 // CompositionRegistry holds all loaded compositions
@@ -125,9 +127,10 @@ func LoadCompositionsFromDir(configDir string) (*CompositionRegistry, error) {
 		},
 	}
 
-	// Maps to track job.yaml -> schema.yaml pairs
-	jobFiles := make(map[string]string)    // job.yaml path -> variant type
-	schemaFiles := make(map[string]string) // variant type -> schema.yaml path
+	// Maps to track job.yaml -> schema.yaml (and optional binding.yaml) pairs
+	jobFiles := make(map[string]string)     // job.yaml path -> variant type
+	schemaFiles := make(map[string]string)  // variant type -> schema.yaml path
+	bindingFiles := make(map[string]string) // variant type -> binding.yaml path
 
 	// Process each search path
 	for _, basePath := range searchPaths {
@@ -143,7 +146,7 @@ func LoadCompositionsFromDir(configDir string) (*CompositionRegistry, error) {
 				}
 
 				filename := info.Name()
-				if filename != "job.yaml" && filename != "schema.yaml" {
+				if filename != "job.yaml" && filename != "schema.yaml" && filename != "binding.yaml" {
 					return nil
 				}
 
@@ -155,6 +158,8 @@ func LoadCompositionsFromDir(configDir string) (*CompositionRegistry, error) {
 					jobFiles[path] = typeName
 				} else if filename == "schema.yaml" {
 					schemaFiles[typeName] = path
+				} else if filename == "binding.yaml" {
+					bindingFiles[typeName] = path
 				}
 
 				return nil
@@ -189,6 +194,12 @@ func LoadCompositionsFromDir(configDir string) (*CompositionRegistry, error) {
 				if _, err := os.Stat(schemaPath); err == nil {
 					schemaFiles[typeName] = schemaPath
 				}
+
+				// Check for optional binding.yaml in this subdirectory
+				bindingPath := filepath.Join(typeDir, "binding.yaml")
+				if _, err := os.Stat(bindingPath); err == nil {
+					bindingFiles[typeName] = bindingPath
+				}
 			}
 		}
 	}
@@ -269,6 +280,7 @@ func LoadCompositionsFromDir(configDir string) (*CompositionRegistry, error) {
 			Schema:          schema,
 			JobRegistryName: jobRegistry.Metadata.Name,
 			JobRegistryDesc: jobRegistry.Metadata.Description,
+			Files:           []string{jobPath, schemaPath},
 		}
 
 		// Build job map for quick lookup by name
@@ -276,6 +288,20 @@ func LoadCompositionsFromDir(configDir string) (*CompositionRegistry, error) {
 			composition.JobMap[jobRegistry.Jobs[i].Name] = &jobRegistry.Jobs[i]
 		}
 
+		// Load optional job binding declaration
+		if bindingPath, ok := bindingFiles[typeName]; ok {
+			binding, err := LoadJobBinding(bindingPath)
+			if err != nil {
+				return nil, err
+			}
+			if err := validateJobBinding(typeName, binding, composition); err != nil {
+				return nil, fmt.Errorf("invalid job binding %s: %w", bindingPath, err)
+			}
+			composition.Bindings = binding
+			composition.Files = append(composition.Files, bindingPath)
+			registry.Bindings[typeName] = binding
+		}
+
 		registry.Types[typeName] = composition
 
 		// Also add jobs to the registry's job list for backward compatibility
@@ -325,3 +351,201 @@ func (reg *CompositionRegistry) ValidateAllComponents(normalized *model.Normaliz
 	}
 	return nil
 }
+
+// LoadJobBinding loads and parses a job binding YAML file
+func LoadJobBinding(path string) (*model.JobBinding, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job binding file: %w", err)
+	}
+
+	var binding model.JobBinding
+	if err := yaml.Unmarshal(data, &binding); err != nil {
+		return nil, fmt.Errorf("failed to parse job binding YAML %s: %w", path, err)
+	}
+
+	return &binding, nil
+}
+
+// validateJobBinding checks that a binding refers to its own composition and
+// only to jobs the composition's JobRegistry defines
+func validateJobBinding(typeName string, binding *model.JobBinding, composition *Composition) error {
+	if binding.Kind != "" && binding.Kind != "JobBinding" {
+		return fmt.Errorf("expected kind JobBinding, got %s", binding.Kind)
+	}
+	if binding.Spec.Model == "" {
+		binding.Spec.Model = typeName
+	}
+	if binding.Spec.Model != typeName {
+		return fmt.Errorf("binding is for model %s but lives in composition %s", binding.Spec.Model, typeName)
+	}
+
+	for _, ref := range binding.Spec.Jobs {
+		if _, exists := composition.JobMap[ref.Name]; !exists {
+			return fmt.Errorf("job %s is not defined in the %s job registry", ref.Name, typeName)
+		}
+	}
+
+	if binding.Spec.DefaultJob != "" {
+		if _, exists := composition.JobMap[binding.Spec.DefaultJob]; !exists {
+			return fmt.Errorf("default job %s is not defined in the %s job registry", binding.Spec.DefaultJob, typeName)
+		}
+		if len(binding.Spec.Jobs) > 0 && !bindingListsJob(binding, binding.Spec.DefaultJob) {
+			return fmt.Errorf("default job %s is not listed in the binding's jobs", binding.Spec.DefaultJob)
+		}
+	}
+
+	return nil
+}
+
+func bindingListsJob(binding *model.JobBinding, jobName string) bool {
+	for _, ref := range binding.Spec.Jobs {
+		if ref.Name == jobName {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultJob returns the job planned when none is requested: the binding's
+// defaultJob if declared, otherwise the first job of the registry
+func (c *Composition) DefaultJob() *model.JobSpec {
+	if c.Bindings != nil && c.Bindings.Spec.DefaultJob != "" {
+		return c.JobMap[c.Bindings.Spec.DefaultJob]
+	}
+	if len(c.Jobs) > 0 {
+		return &c.Jobs[0]
+	}
+	return nil
+}
+
+// AvailableJobs returns the jobs that may be planned. A binding that lists
+// jobs restricts the selection to those; otherwise every registry job is available
+func (c *Composition) AvailableJobs() map[string]*model.JobSpec {
+	if c.Bindings == nil || len(c.Bindings.Spec.Jobs) == 0 {
+		return c.JobMap
+	}
+
+	available := make(map[string]*model.JobSpec, len(c.Bindings.Spec.Jobs))
+	for _, ref := range c.Bindings.Spec.Jobs {
+		available[ref.Name] = c.JobMap[ref.Name]
+	}
+	return available
+}
+
+// RequiredJobs returns the jobs the binding marks as required, in declaration order
+func (c *Composition) RequiredJobs() []*model.JobSpec {
+	if c.Bindings == nil {
+		return nil
+	}
+
+	required := make([]*model.JobSpec, 0)
+	for _, ref := range c.Bindings.Spec.Jobs {
+		if ref.Required {
+			required = append(required, c.JobMap[ref.Name])
+		}
+	}
+	return required
+}
+
+// ValidateConstraints checks a composition's binding constraints against an
+// environment. Platforms must overlap with the environment's declared platforms
+// and the tool version the environment declares for the model must be at least
+// minVersion. A constraint the environment declares nothing to check against
+// cannot be enforced; it is returned as a warning instead of an error.
+func (reg *CompositionRegistry) ValidateConstraints(typeName, envName string, env model.Environment) ([]string, error) {
+	composition, exists := reg.Types[typeName]
+	if !exists || composition.Bindings == nil {
+		return nil, nil
+	}
+	constraints := composition.Bindings.Spec.Constraints
+
+	var warnings []string
+	if len(constraints.Platforms) > 0 && len(env.Platforms) == 0 {
+		warnings = append(warnings, fmt.Sprintf("composition %s requires platform %s but environment %s declares no platforms; not checked",
+			typeName, strings.Join(constraints.Platforms, " or "), envName))
+	}
+	if len(constraints.Platforms) > 0 && len(env.Platforms) > 0 {
+		supported := false
+		for _, platform := range constraints.Platforms {
+			for _, envPlatform := range env.Platforms {
+				if platform == envPlatform {
+					supported = true
+				}
+			}
+		}
+		if !supported {
+			return nil, fmt.Errorf("composition %s requires platform %s but environment %s provides %s",
+				typeName, strings.Join(constraints.Platforms, " or "), envName, strings.Join(env.Platforms, ", "))
+		}
+	}
+
+	if constraints.MinVersion != "" {
+		tool := composition.Bindings.Spec.Model
+		version, ok := env.Tools[tool]
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("composition %s requires %s >= %s but environment %s declares no %s version; not checked",
+				typeName, tool, constraints.MinVersion, envName, tool))
+		} else {
+			cmp, err := compareVersions(version, constraints.MinVersion)
+			if err != nil {
+				return nil, fmt.Errorf("cannot check %s version for environment %s: %w", tool, envName, err)
+			}
+			if cmp < 0 {
+				return nil, fmt.Errorf("composition %s requires %s >= %s but environment %s provides %s",
+					typeName, tool, constraints.MinVersion, envName, version)
+			}
+		}
+	}
+
+	return warnings, nil
+}
+
+// compareVersions compares dotted numeric versions such as "v3.12.1",
+// returning -1, 0 or 1. Missing components count as zero.
+func compareVersions(a, b string) (int, error) {
+	partsA, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	partsB, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := 0; i < len(partsA) || i < len(partsB); i++ {
+		var x, y int
+		if i < len(partsA) {
+			x = partsA[i]
+		}
+		if i < len(partsB) {
+			y = partsB[i]
+		}
+		if x < y {
+			return -1, nil
+		}
+		if x > y {
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func parseVersion(version string) ([]int, error) {
+	trimmed := strings.TrimPrefix(strings.TrimSpace(version), "v")
+	// Ignore pre-release and build metadata (3.12.0-rc.1+abc)
+	if idx := strings.IndexAny(trimmed, "-+"); idx >= 0 {
+		trimmed = trimmed[:idx]
+	}
+
+	parts := strings.Split(trimmed, ".")
+	result := make([]int, 0, len(parts))
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q", version)
+		}
+		result = append(result, n)
+	}
+	return result, nil
+}
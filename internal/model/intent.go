@@ -30,12 +30,17 @@ type Environment struct {
 	Selectors EnvironmentSelectors   `yaml:"selectors" json:"selectors"`
 	Defaults  map[string]interface{} `yaml:"defaults" json:"defaults"`
 	Policies  map[string]interface{} `yaml:"policies" json:"policies"`
+	Platforms []string               `yaml:"platforms,omitempty" json:"platforms,omitempty"` // Execution platforms (kubernetes, docker, ...) checked against JobBinding constraints
+	Tools     map[string]string      `yaml:"tools,omitempty" json:"tools,omitempty"`         // Tool (model) name -> version, checked against JobBinding minVersion
 }
 
-// EnvironmentSelectors specifies which components apply to an environment
+// EnvironmentSelectors specifies which components apply to an environment.
+// Entries are globs (web-*) or, for labels, selectors such as tier=frontend;
+// an entry prefixed with "!" excludes the components it matches.
 type EnvironmentSelectors struct {
 	Components []string `yaml:"components" json:"components"`
 	Domains    []string `yaml:"domains" json:"domains"`
+	Labels     []string `yaml:"labels,omitempty" json:"labels,omitempty"`
 }
 
 // Component is execution-agnostic declaration
@@ -45,6 +50,8 @@ type Component struct {
 	Domain    string                 `yaml:"domain" json:"domain"`
 	Enabled   bool                   `yaml:"enabled" json:"enabled"`
 	Path      string                 `yaml:"path" json:"path"`
+	Job       string                 `yaml:"job,omitempty" json:"job,omitempty"`   // Job from the composition to plan (default: composition default job)
+	Jobs      []string               `yaml:"jobs,omitempty" json:"jobs,omitempty"` // Ordered jobs to plan, e.g. [build, test, deploy]
 	Inputs    map[string]interface{} `yaml:"inputs" json:"inputs"`
 	Labels    map[string]string      `yaml:"labels" json:"labels"`
 	DependsOn []Dependency           `yaml:"dependsOn" json:"dependsOn"`
@@ -58,6 +65,20 @@ type Dependency struct {
 	Condition   string `yaml:"condition" json:"condition"` // success, always, failure
 }
 
+// Dependency scopes say which environment the dependency target is planned in
+const (
+	ScopeSameEnvironment  = "same-environment"  // the dependent's own environment
+	ScopeCrossEnvironment = "cross-environment" // the environment named by Dependency.Environment
+)
+
+// Dependency conditions decide whether a dependent runs given the outcome of
+// the job it depends on
+const (
+	ConditionSuccess = "success" // run only if the dependency succeeded
+	ConditionAlways  = "always"  // run once the dependency finished, whatever its outcome
+	ConditionFailure = "failure" // run only if the dependency failed
+)
+
 // NormalizedIntent is the canonical internal representation
 type NormalizedIntent struct {
 	Metadata       Metadata
@@ -74,11 +95,15 @@ type ComponentInstance struct {
 	Type          string
 	Domain        string
 	Path          string
+	Job           string   // Requested job name; empty selects the composition default
+	Jobs          []string // Requested job sequence; takes precedence over Job
 	Labels        map[string]string
 	Inputs        map[string]interface{}
 	Policies      map[string]interface{}
 	DependsOn     []ResolvedDependency
 	Enabled       bool
+	SelectedBy    []string // Environment selectors that matched the component
+	RequiredBy    []string // Planned instances (component@environment) that depend on this one
 }
 
 // ResolvedDependency is a dependency with resolved target component
@@ -66,6 +66,8 @@ type JobInstance struct {
 	Path        string
 	Steps       []RenderedStep
 	DependsOn   []string
+	Conditions  map[string]string // Dependency job ID -> condition (success, always, failure)
+	Gates       []Gate
 	Timeout     string
 	Retries     int
 	Config      map[string]interface{} // Single source of truth for env vars
@@ -2,42 +2,100 @@ package model
 
 // Plan is the final execution-ready workflow DAG
 type Plan struct {
-	APIVersion string              `json:"apiVersion"`
-	Kind       string              `json:"kind"`
-	Metadata   Metadata            `json:"metadata"`
-	Spec       PlanSpec            `json:"spec"`
-	Jobs       []PlanJob           `json:"jobs"`
+	APIVersion string              `yaml:"apiVersion" json:"apiVersion"`
+	Kind       string              `yaml:"kind" json:"kind"`
+	Metadata   PlanMetadata        `yaml:"metadata" json:"metadata"`
+	Spec       PlanSpec            `yaml:"spec" json:"spec"`
+	Jobs       []PlanJob           `yaml:"jobs" json:"jobs"`
+}
+
+// PlanMetadata identifies a plan and records how it was scoped
+type PlanMetadata struct {
+	Name         string      `yaml:"name" json:"name"`
+	Description  string      `yaml:"description" json:"description"`
+	Namespace    string      `yaml:"namespace" json:"namespace"`
+	Environments []string    `yaml:"environments,omitempty" json:"environments,omitempty"` // Environment names or globs the plan was restricted to (--env)
+	Provenance   *Provenance `yaml:"provenance,omitempty" json:"provenance,omitempty"`   // Inputs and tool the plan was compiled from
+	Digest       string      `yaml:"digest,omitempty" json:"digest,omitempty"`       // sha256 of the plan's canonical JSON with this field empty
+}
+
+// Provenance records what a plan was compiled from, so a deploy can be traced
+// back to its inputs
+type Provenance struct {
+	Liteci       ToolVersion   `yaml:"liteci" json:"liteci"`
+	Intent       FileDigest    `yaml:"intent" json:"intent"`
+	Compositions []FileDigest  `yaml:"compositions" json:"compositions"`      // job.yaml, schema.yaml and binding.yaml of the compositions the jobs use
+	Git          *GitRevision  `yaml:"git,omitempty" json:"git,omitempty"`     // Revision of the working directory, when it is a git repository
+	Changes      *ChangeFilter `yaml:"changes,omitempty" json:"changes,omitempty"` // Change detection options in effect (--changed)
+}
+
+// ToolVersion identifies the liteci build that compiled a plan
+type ToolVersion struct {
+	Version string `yaml:"version" json:"version"`
+	Commit  string `yaml:"commit,omitempty" json:"commit,omitempty"`
+}
+
+// FileDigest is the sha256 of an input file
+type FileDigest struct {
+	Path   string `yaml:"path" json:"path"`
+	SHA256 string `yaml:"sha256" json:"sha256"`
+}
+
+// GitRevision is the commit a plan was compiled at
+type GitRevision struct {
+	Commit string `yaml:"commit" json:"commit"`
+	Dirty  bool   `yaml:"dirty,omitempty" json:"dirty,omitempty"` // The working tree had uncommitted changes
+}
+
+// ChangeFilter records the change detection options a plan was filtered with
+type ChangeFilter struct {
+	Base        string   `yaml:"base,omitempty" json:"base,omitempty"`
+	Head        string   `yaml:"head,omitempty" json:"head,omitempty"`
+	Files       []string `yaml:"files,omitempty" json:"files,omitempty"`
+	Uncommitted bool     `yaml:"uncommitted,omitempty" json:"uncommitted,omitempty"`
+	Untracked   bool     `yaml:"untracked,omitempty" json:"untracked,omitempty"`
 }
 
 // PlanSpec holds specification about the plan and its bindings
 type PlanSpec struct {
-	JobBindings map[string]string `json:"jobBindings"` // model -> JobRegistry name mapping
+	JobBindings map[string]string `yaml:"jobBindings" json:"jobBindings"` // model -> JobRegistry name mapping
 }
 
 // PlanJob is the execution unit in the final plan
 type PlanJob struct {
-	ID          string                 `json:"id"`
-	Name        string                 `json:"name"`
-	Component   string                 `json:"component"`
-	Environment string                 `json:"environment"`
-	Composition string                 `json:"composition"`
-	JobRegistry string                 `json:"jobRegistry"`          // Name of the JobRegistry used
-	Job         string                 `json:"job"`                  // Specific job from registry
-	Path        string                 `json:"path"`                // Working directory for job execution
-	Steps       []PlanStep             `json:"steps"`
-	DependsOn   []string               `json:"dependsOn"`
-	Timeout     string                 `json:"timeout"`
-	Retries     int                    `json:"retries"`
-	Env         map[string]interface{} `json:"env"`
-	Labels      map[string]string      `json:"labels"`
-	Config      map[string]interface{} `json:"config"`
+	ID          string                 `yaml:"id" json:"id"`
+	Name        string                 `yaml:"name" json:"name"`
+	Component   string                 `yaml:"component" json:"component"`
+	Environment string                 `yaml:"environment" json:"environment"`
+	Composition string                 `yaml:"composition" json:"composition"`
+	JobRegistry string                 `yaml:"jobRegistry" json:"jobRegistry"`          // Name of the JobRegistry used
+	Job         string                 `yaml:"job" json:"job"`                  // Specific job from registry
+	Path        string                 `yaml:"path" json:"path"`                // Working directory for job execution
+	Steps       []PlanStep             `yaml:"steps" json:"steps"`
+	DependsOn   []string               `yaml:"dependsOn" json:"dependsOn"`
+	Conditions  map[string]string      `yaml:"conditions,omitempty" json:"conditions,omitempty"` // Dependency job ID -> condition (success, always, failure)
+	Gates       []Gate                 `yaml:"gates,omitempty" json:"gates,omitempty"`      // Gates that must be unlocked before the job runs
+	Timeout     string                 `yaml:"timeout" json:"timeout"`
+	Retries     int                    `yaml:"retries" json:"retries"`
+	Env         map[string]interface{} `yaml:"env" json:"env"`
+	Labels      map[string]string      `yaml:"labels" json:"labels"`
+	Config      map[string]interface{} `yaml:"config" json:"config"`
+}
+
+// GateApproval is a gate unlocked by a human approval
+const GateApproval = "approval"
+
+// Gate holds a job back at run time until it is unlocked
+type Gate struct {
+	Type   string `yaml:"type" json:"type"`   // approval
+	Reason string `yaml:"reason" json:"reason"` // Policy that introduced the gate
 }
 
 // PlanStep is a step in the final plan
 type PlanStep struct {
-	Name      string `json:"name"`
-	Run       string `json:"run"`
-	Timeout   string `json:"timeout,omitempty"`
-	Retry     int    `json:"retry,omitempty"`
-	OnFailure string `json:"onFailure,omitempty"`
+	Name      string `yaml:"name" json:"name"`
+	Run       string `yaml:"run" json:"run"`
+	Timeout   string `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	Retry     int    `yaml:"retry,omitempty" json:"retry,omitempty"`
+	OnFailure string `yaml:"onFailure,omitempty" json:"onFailure,omitempty"`
 }
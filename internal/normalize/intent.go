@@ -2,7 +2,6 @@ package normalize
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/sourceplane/liteci/internal/model"
 )
@@ -32,6 +31,10 @@ func NormalizeIntent(intent *model.Intent) (*model.NormalizedIntent, error) {
 			return nil, fmt.Errorf("component %s must have a type", comp.Name)
 		}
 
+		if comp.Job != "" && len(comp.Jobs) > 0 {
+			return nil, fmt.Errorf("component %s sets both job and jobs; use one of them", comp.Name)
+		}
+
 		// Set enabled default
 		if !comp.Enabled && comp.Enabled != true {
 			comp.Enabled = true
@@ -55,13 +58,41 @@ func NormalizeIntent(intent *model.Intent) (*model.NormalizedIntent, error) {
 			if dep.Environment == "" {
 				dep.Environment = "__same__"
 			}
-			// Default scope
+			// Default scope: naming an environment makes the dependency cross-environment
 			if dep.Scope == "" {
-				dep.Scope = "same-environment"
+				dep.Scope = model.ScopeSameEnvironment
+				if dep.Environment != "__same__" {
+					dep.Scope = model.ScopeCrossEnvironment
+				}
+			}
+			switch dep.Scope {
+			case model.ScopeSameEnvironment:
+				if dep.Environment != "__same__" {
+					return nil, fmt.Errorf("component %s depends on %s in environment %s but its scope is %s; use scope: %s",
+						comp.Name, dep.Component, dep.Environment, dep.Scope, model.ScopeCrossEnvironment)
+				}
+			case model.ScopeCrossEnvironment:
+				if dep.Environment == "__same__" {
+					return nil, fmt.Errorf("component %s has a %s dependency on %s without an environment",
+						comp.Name, dep.Scope, dep.Component)
+				}
+				if _, exists := intent.Environments[dep.Environment]; !exists {
+					return nil, fmt.Errorf("component %s depends on %s in environment %s, which is not defined",
+						comp.Name, dep.Component, dep.Environment)
+				}
+			default:
+				return nil, fmt.Errorf("component %s has invalid scope %q for dependency %s (expected %s or %s)",
+					comp.Name, dep.Scope, dep.Component, model.ScopeSameEnvironment, model.ScopeCrossEnvironment)
 			}
 			// Default condition
 			if dep.Condition == "" {
-				dep.Condition = "success"
+				dep.Condition = model.ConditionSuccess
+			}
+			switch dep.Condition {
+			case model.ConditionSuccess, model.ConditionAlways, model.ConditionFailure:
+			default:
+				return nil, fmt.Errorf("component %s has invalid condition %q for dependency %s (expected success, always or failure)",
+					comp.Name, dep.Condition, dep.Component)
 			}
 		}
 
@@ -69,7 +100,7 @@ func NormalizeIntent(intent *model.Intent) (*model.NormalizedIntent, error) {
 		normalized.ComponentIndex[comp.Name] = comp
 	}
 
-	// Validate environment selectors
+	// Default environment selectors
 	for envName, env := range normalized.Environments {
 		if env.Selectors.Components == nil {
 			env.Selectors.Components = []string{}
@@ -77,14 +108,8 @@ func NormalizeIntent(intent *model.Intent) (*model.NormalizedIntent, error) {
 		if env.Selectors.Domains == nil {
 			env.Selectors.Domains = []string{}
 		}
-
-		// Expand wildcards
-		if contains(env.Selectors.Components, "*") {
-			expandedComps := make([]string, 0)
-			for compName := range normalized.ComponentIndex {
-				expandedComps = append(expandedComps, compName)
-			}
-			env.Selectors.Components = expandedComps
+		if env.Selectors.Labels == nil {
+			env.Selectors.Labels = []string{}
 		}
 
 		normalized.Environments[envName] = env
@@ -92,25 +117,3 @@ func NormalizeIntent(intent *model.Intent) (*model.NormalizedIntent, error) {
 
 	return normalized, nil
 }
-
-// contains checks if a slice contains a string
-func contains(slice []string, item string) bool {
-	for _, v := range slice {
-		if v == item {
-			return true
-		}
-	}
-	return false
-}
-
-// matchesWildcard checks if a component name matches a wildcard pattern
-func matchesWildcard(pattern, name string) bool {
-	if pattern == "*" {
-		return true
-	}
-	if strings.HasSuffix(pattern, "*") {
-		prefix := strings.TrimSuffix(pattern, "*")
-		return strings.HasPrefix(name, prefix)
-	}
-	return pattern == name
-}
@@ -2,6 +2,7 @@ package planner
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/sourceplane/liteci/internal/model"
 )
@@ -59,7 +60,8 @@ func (g *JobGraph) hasCycleDFS(node string, visited, recStack map[string]bool) b
 }
 
 // TopologicalSort performs topological sorting of jobs using Kahn's algorithm
-// Returns sorted job IDs in execution order
+// Returns sorted job IDs in execution order; ties are broken by job ID so the
+// order is the same on every run
 func (g *JobGraph) TopologicalSort() ([]string, error) {
 	// Build reverse dependency graph (dependents: who depends on me)
 	dependents := make(map[string][]string)
@@ -79,6 +81,10 @@ func (g *JobGraph) TopologicalSort() ([]string, error) {
 		}
 	}
 
+	for _, list := range dependents {
+		sort.Strings(list)
+	}
+
 	// Kahn's algorithm: process nodes with no dependencies first
 	queue := make([]string, 0)
 	for jobID, degree := range inDegree {
@@ -86,6 +92,7 @@ func (g *JobGraph) TopologicalSort() ([]string, error) {
 			queue = append(queue, jobID)
 		}
 	}
+	sort.Strings(queue)
 
 	sorted := make([]string, 0, len(g.jobs))
 	for len(queue) > 0 {
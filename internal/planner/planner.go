@@ -2,22 +2,27 @@ package planner
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"text/template"
 
 	"github.com/sourceplane/liteci/internal/model"
+	"github.com/sourceplane/liteci/internal/policy"
 )
 
 // JobPlanner binds components to jobs and creates instances
 type JobPlanner struct {
 	compositions    map[string]*CompositionInfo // Composition -> default job info
 	templateCache   map[string]*template.Template
+	jobOverride     string // Job to plan for every component, set via SelectJob
 }
 
-// CompositionInfo holds the default job for a composition
+// CompositionInfo holds the default job and all named jobs for a composition
 type CompositionInfo struct {
-	Type       string
-	DefaultJob *model.JobSpec
+	Type         string
+	DefaultJob   *model.JobSpec
+	Jobs         map[string]*model.JobSpec // Jobs that may be planned, by name
+	RequiredJobs []*model.JobSpec          // Jobs always planned alongside the selected job
 }
 
 // NewJobPlanner creates a new job planner from a composition registry
@@ -28,9 +33,20 @@ func NewJobPlanner(compositions map[string]*CompositionInfo) *JobPlanner {
 	}
 }
 
-// PlanJobs creates job instances from component instances
+// SelectJob makes every component plan the named job instead of its
+// component-level job or the composition default (e.g. "rollback" or "diff")
+func (jp *JobPlanner) SelectJob(name string) {
+	jp.jobOverride = name
+}
+
+// PlanJobs creates job instances from component instances.
+// Each component instance plans a chain of jobs (for example build → test →
+// deploy): every job in the chain depends on the previous one, so the first job
+// is the chain's entry point and the last one its terminal job. Gates required
+// by the instance's policies are placed on the entry job.
 func (jp *JobPlanner) PlanJobs(instances map[string][]*model.ComponentInstance) (map[string]*model.JobInstance, error) {
 	jobInstances := make(map[string]*model.JobInstance)
+	chains := make(map[string][]string) // key: "comp@env", value: ordered job IDs
 
 	for envName, envInstances := range instances {
 		for _, compInst := range envInstances {
@@ -40,48 +56,124 @@ func (jp *JobPlanner) PlanJobs(instances map[string][]*model.ComponentInstance)
 				return nil, fmt.Errorf("no job definition for type: %s", compInst.Type)
 			}
 
-			jobDef := compositionInfo.DefaultJob
-			if jobDef == nil {
-				return nil, fmt.Errorf("no default job defined for type: %s", compInst.Type)
+			jobDefs, err := jp.resolveJobs(compositionInfo, compInst)
+			if err != nil {
+				return nil, err
 			}
 
-			// Create job instance
-			jobID := fmt.Sprintf("%s@%s.%s", compInst.ComponentName, envName, jobDef.Name)
-			jobInst := &model.JobInstance{
-				ID:          jobID,
-				Name:        jobDef.Name,
-				Component:   compInst.ComponentName,
-				Environment: envName,
-				Composition: compInst.Type,
-				Path:        compInst.Path,
-				Timeout:     jobDef.Timeout,
-				Retries:     jobDef.Retries,
-				Labels:      compInst.Labels,
-				Config:      compInst.Inputs,
-				DependsOn:   make([]string, 0),
-			}
+			key := fmt.Sprintf("%s@%s", compInst.ComponentName, envName)
+			for _, jobDef := range jobDefs {
+				// Create job instance
+				jobID := fmt.Sprintf("%s@%s.%s", compInst.ComponentName, envName, jobDef.Name)
+				jobInst := &model.JobInstance{
+					ID:          jobID,
+					Name:        jobDef.Name,
+					Component:   compInst.ComponentName,
+					Environment: envName,
+					Composition: compInst.Type,
+					Path:        compInst.Path,
+					Timeout:     jobDef.Timeout,
+					Retries:     jobDef.Retries,
+					Labels:      compInst.Labels,
+					Config:      compInst.Inputs,
+					DependsOn:   make([]string, 0),
+					Conditions:  make(map[string]string),
+				}
 
-			// Render steps with template variables
-			renderedSteps, err := jp.renderSteps(jobDef.Steps, compInst)
-			if err != nil {
-				return nil, fmt.Errorf("failed to render steps for job %s: %w", jobID, err)
+				// Render steps with template variables
+				renderedSteps, err := jp.renderSteps(jobDef, compInst)
+				if err != nil {
+					return nil, fmt.Errorf("failed to render steps for job %s: %w", jobID, err)
+				}
+				jobInst.Steps = renderedSteps
+
+				// Order jobs within the component
+				if chain := chains[key]; len(chain) > 0 {
+					previous := chain[len(chain)-1]
+					jobInst.DependsOn = append(jobInst.DependsOn, previous)
+					jobInst.Conditions[previous] = model.ConditionSuccess
+				}
+
+				jobInstances[jobID] = jobInst
+				chains[key] = append(chains[key], jobID)
 			}
-			jobInst.Steps = renderedSteps
 
-			jobInstances[jobID] = jobInst
+			// Approval policies gate the chain's entry job, holding back the
+			// whole chain until it is approved
+			if chain := chains[key]; len(chain) > 0 {
+				jobInstances[chain[0]].Gates = policy.Gates(compInst.Policies)
+			}
 		}
 	}
 
 	// Resolve job dependencies
-	err := jp.resolveDependencies(jobInstances, instances)
+	err := jp.resolveDependencies(jobInstances, chains, instances)
 	if err != nil {
 		return nil, err
 	}
 
 	return jobInstances, nil
 }
+
+// resolveJobs returns the ordered chain of jobs to plan for a component instance.
+// The requested jobs come from the planner-wide override, then the jobs or job
+// requested by the component, then the composition default. Jobs the binding
+// marks as required that were not requested run first, in binding order.
+func (jp *JobPlanner) resolveJobs(compositionInfo *CompositionInfo, compInst *model.ComponentInstance) ([]*model.JobSpec, error) {
+	var names []string
+	var source string
+	switch {
+	case jp.jobOverride != "":
+		names, source = []string{jp.jobOverride}, "--job"
+	case len(compInst.Jobs) > 0:
+		names, source = compInst.Jobs, "component "+compInst.ComponentName
+	case compInst.Job != "":
+		names, source = []string{compInst.Job}, "component "+compInst.ComponentName
+	}
+
+	requested := make([]*model.JobSpec, 0, len(names))
+	if len(names) == 0 {
+		if compositionInfo.DefaultJob == nil {
+			return nil, fmt.Errorf("no default job defined for type: %s", compInst.Type)
+		}
+		requested = append(requested, compositionInfo.DefaultJob)
+	}
+
+	for _, name := range names {
+		jobDef, exists := compositionInfo.Jobs[name]
+		if !exists {
+			available := make([]string, 0, len(compositionInfo.Jobs))
+			for jobName := range compositionInfo.Jobs {
+				available = append(available, jobName)
+			}
+			sort.Strings(available)
+			return nil, fmt.Errorf("composition %s does not define job %q (requested by %s; available: %s)",
+				compInst.Type, name, source, strings.Join(available, ", "))
+		}
+		requested = append(requested, jobDef)
+	}
+
+	seen := make(map[string]bool)
+	for _, jobDef := range requested {
+		if seen[jobDef.Name] {
+			return nil, fmt.Errorf("job %q is listed more than once for %s", jobDef.Name, source)
+		}
+		seen[jobDef.Name] = true
+	}
+
+	jobDefs := make([]*model.JobSpec, 0, len(requested)+len(compositionInfo.RequiredJobs))
+	for _, required := range compositionInfo.RequiredJobs {
+		if !seen[required.Name] {
+			jobDefs = append(jobDefs, required)
+		}
+	}
+	return append(jobDefs, requested...), nil
+}
+
+// renderSteps renders the steps of a job for a component instance.
 // Templates are cached to avoid re-parsing identical steps across multiple instances
-func (jp *JobPlanner) renderSteps(steps []model.Step, compInst *model.ComponentInstance) ([]model.RenderedStep, error) {
+func (jp *JobPlanner) renderSteps(jobDef *model.JobSpec, compInst *model.ComponentInstance) ([]model.RenderedStep, error) {
+	steps := jobDef.Steps
 	rendered := make([]model.RenderedStep, 0, len(steps))
 
 	// Build template context once
@@ -97,8 +189,8 @@ func (jp *JobPlanner) renderSteps(steps []model.Step, compInst *model.ComponentI
 	}
 
 	for _, step := range steps {
-		// Use cache key: componentType:stepName (steps are unique within a job type)
-		cacheKey := fmt.Sprintf("%s:%s", compInst.Type, step.Name)
+		// Use cache key: componentType:jobName:stepName (steps are unique within a job)
+		cacheKey := fmt.Sprintf("%s:%s:%s", compInst.Type, jobDef.Name, step.Name)
 
 		// Check cache first
 		tmpl, exists := jp.templateCache[cacheKey]
@@ -130,38 +222,39 @@ func (jp *JobPlanner) renderSteps(steps []model.Step, compInst *model.ComponentI
 	return rendered, nil
 }
 
-// resolveDependencies sets up dependency edges between job instances
-func (jp *JobPlanner) resolveDependencies(jobInstances map[string]*model.JobInstance, compInstances map[string][]*model.ComponentInstance) error {
-	// Build a map for fast lookup: (component, environment) -> job IDs
-	compToJobs := make(map[string][]string) // key: "comp@env", value: [jobIDs]
-
-	for jobID, job := range jobInstances {
-		key := fmt.Sprintf("%s@%s", job.Component, job.Environment)
-		compToJobs[key] = append(compToJobs[key], jobID)
-	}
-
+// resolveDependencies sets up dependency edges between job instances.
+// A component's entry job depends on the terminal job of each component it
+// depends on, so a dependency's whole chain finishes before the dependent starts.
+func (jp *JobPlanner) resolveDependencies(jobInstances map[string]*model.JobInstance, chains map[string][]string, compInstances map[string][]*model.ComponentInstance) error {
 	// For each component instance, resolve its dependencies
 	for envName, envInstances := range compInstances {
 		for _, compInst := range envInstances {
-			// Get all jobs for this component
 			key := fmt.Sprintf("%s@%s", compInst.ComponentName, envName)
-			myJobs, exists := compToJobs[key]
-			if !exists {
+			myJobs, exists := chains[key]
+			if !exists || len(myJobs) == 0 {
 				continue
 			}
+			entryJob := jobInstances[myJobs[0]]
 
 			// Resolve each dependency
 			for _, dep := range compInst.DependsOn {
 				depKey := fmt.Sprintf("%s@%s", dep.ComponentName, dep.Environment)
-				depJobs, exists := compToJobs[depKey]
-				if !exists {
+				depJobs, exists := chains[depKey]
+				if !exists || len(depJobs) == 0 {
 					return fmt.Errorf("dependency not found: %s depends on %s", key, depKey)
 				}
+				terminalJob := depJobs[len(depJobs)-1]
+
+				condition := dep.Condition
+				if condition == "" {
+					condition = model.ConditionSuccess
+				}
 
-				// Link all my jobs to all dependency jobs
-				for _, myJob := range myJobs {
-					jobInstances[myJob].DependsOn = append(jobInstances[myJob].DependsOn, depJobs...)
+				// Link my entry job to the dependency's terminal job, keeping the edge condition
+				if _, linked := entryJob.Conditions[terminalJob]; !linked {
+					entryJob.DependsOn = append(entryJob.DependsOn, terminalJob)
 				}
+				entryJob.Conditions[terminalJob] = condition
 			}
 		}
 	}
@@ -0,0 +1,102 @@
+package policy
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/sourceplane/liteci/internal/model"
+)
+
+// Violation is a policy rule a component instance does not satisfy
+type Violation struct {
+	Component   string
+	Environment string
+	Rule        string // Rule kind, e.g. requiredLabels
+	Source      string // Where the rule is declared, e.g. "group platform"
+	Message     string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s@%s: %s (%s): %s", v.Component, v.Environment, v.Rule, v.Source, v.Message)
+}
+
+// Engine evaluates the rules declared in group and environment policies
+// against expanded component instances
+type Engine struct {
+	Strict bool // Reject policies that are not rules instead of warning about them
+
+	normalized *model.NormalizedIntent
+}
+
+// NewEngine creates a policy engine for a normalized intent
+func NewEngine(normalized *model.NormalizedIntent) *Engine {
+	return &Engine{normalized: normalized}
+}
+
+// Evaluate checks every instance against the policies of its group (domain)
+// and its environment. Group and environment rules are evaluated separately so
+// an environment cannot relax a rule its group declares. It also returns a
+// warning per policy that is not evaluated, and an error only when a policy
+// declaration itself is malformed (or, with Strict, is not a rule).
+func (e *Engine) Evaluate(instances map[string][]*model.ComponentInstance) ([]Violation, []string, error) {
+	var warnings []string
+
+	groupRules := make(map[string][]Rule)
+	for _, name := range sortedKeys(e.normalized.Groups) {
+		rules, ruleWarnings, err := ParseRules(e.normalized.Groups[name].Policies, e.Strict)
+		if err != nil {
+			return nil, nil, fmt.Errorf("group %s: %w", name, err)
+		}
+		for _, w := range ruleWarnings {
+			warnings = append(warnings, fmt.Sprintf("group %s: %s", name, w))
+		}
+		groupRules[name] = rules
+	}
+
+	envRules := make(map[string][]Rule)
+	for _, name := range sortedKeys(e.normalized.Environments) {
+		rules, ruleWarnings, err := ParseRules(e.normalized.Environments[name].Policies, e.Strict)
+		if err != nil {
+			return nil, nil, fmt.Errorf("environment %s: %w", name, err)
+		}
+		for _, w := range ruleWarnings {
+			warnings = append(warnings, fmt.Sprintf("environment %s: %s", name, w))
+		}
+		envRules[name] = rules
+	}
+
+	violations := make([]Violation, 0)
+	for envName, envInstances := range instances {
+		for _, inst := range envInstances {
+			if inst.Domain != "" {
+				violations = append(violations, check(inst, groupRules[inst.Domain], "group "+inst.Domain)...)
+			}
+			violations = append(violations, check(inst, envRules[envName], "environment "+envName)...)
+		}
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].Component != violations[j].Component {
+			return violations[i].Component < violations[j].Component
+		}
+		return violations[i].Environment < violations[j].Environment
+	})
+
+	return violations, warnings, nil
+}
+
+func check(inst *model.ComponentInstance, rules []Rule, source string) []Violation {
+	var violations []Violation
+	for _, rule := range rules {
+		for _, message := range rule.Check(inst) {
+			violations = append(violations, Violation{
+				Component:   inst.ComponentName,
+				Environment: inst.Environment,
+				Rule:        rule.Kind(),
+				Source:      source,
+				Message:     message,
+			})
+		}
+	}
+	return violations
+}
@@ -0,0 +1,53 @@
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sourceplane/liteci/internal/model"
+)
+
+// Policy keys that require a human approval before a component's jobs run,
+// e.g. requireApproval: "true" on an environment or approval: required on a group
+var approvalKeys = []string{"requireApproval", "approval"}
+
+// Gates returns the run-time gates a component instance's policies require
+func Gates(policies map[string]interface{}) []model.Gate {
+	var reasons []string
+	for _, key := range approvalKeys {
+		value, ok := policies[key]
+		if !ok || !truthy(value) {
+			continue
+		}
+		reasons = append(reasons, fmt.Sprintf("policy %s: %v", key, value))
+	}
+	if len(reasons) == 0 {
+		return nil
+	}
+	sort.Strings(reasons)
+
+	return []model.Gate{{Type: model.GateApproval, Reason: strings.Join(reasons, ", ")}}
+}
+
+func isApprovalKey(key string) bool {
+	for _, k := range approvalKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func truthy(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true", "yes", "required":
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,419 @@
+package policy
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sourceplane/liteci/internal/model"
+)
+
+// Built-in rule kinds, declared as keys of a group's or environment's policies.
+const (
+	RuleAllowedEnvironments = "allowedEnvironments" // [production, staging-*]
+	RuleRequiredLabels      = "requiredLabels"      // [team, tier] or {tier: frontend}
+	RuleForbiddenInputs     = "forbiddenInputs"     // [debug, hostNetwork]
+	RuleInputConstraints    = "inputConstraints"    // {replicas: {min: 2, max: 10}, logLevel: {enum: [info, warn]}}
+	RuleIsolation           = "isolation"           // strict: dependencies stay in the instance's environment
+)
+
+// PolicyEnvironment is shorthand for a single allowed environment:
+// environment: production-only is allowedEnvironments: [production]
+const PolicyEnvironment = "environment"
+
+// Isolation levels accepted by the isolation policy
+const (
+	IsolationStrict = "strict"
+	IsolationNone   = "none"
+)
+
+// Rule checks a component instance and returns a message per violation
+type Rule interface {
+	Kind() string
+	Check(inst *model.ComponentInstance) []string
+}
+
+// ParseRules builds the rules declared in a policies map. Approval keys are
+// accepted as they are turned into gates (see Gates). Any other key that is not
+// a built-in rule kind is free-form metadata and is returned as a warning, so a
+// misspelt rule is reported rather than silently ignored; so is an environment
+// or isolation value the shorthand does not understand. With strict, both are
+// errors instead.
+func ParseRules(policies map[string]interface{}, strict bool) ([]Rule, []string, error) {
+	keys := make([]string, 0, len(policies))
+	for key := range policies {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	rules := make([]Rule, 0)
+	var warnings []string
+	for _, key := range keys {
+		value := policies[key]
+
+		var rule Rule
+		var err error
+		switch key {
+		case RuleAllowedEnvironments:
+			rule, err = parseAllowedEnvironments(value)
+		case RuleRequiredLabels:
+			rule, err = parseRequiredLabels(value)
+		case RuleForbiddenInputs:
+			rule, err = parseForbiddenInputs(value)
+		case RuleInputConstraints:
+			rule, err = parseInputConstraints(value)
+		case RuleIsolation:
+			rule, err = parseIsolation(value)
+		case PolicyEnvironment:
+			rule, err = parseEnvironmentShorthand(value)
+		default:
+			if isApprovalKey(key) {
+				continue
+			}
+			if strict {
+				return nil, nil, fmt.Errorf("unknown policy %q (expected one of: %s)", key, strings.Join(knownPolicies(), ", "))
+			}
+			warnings = append(warnings, fmt.Sprintf("policy %q is not a rule and is not evaluated (rules: %s)", key, strings.Join(knownPolicies(), ", ")))
+			continue
+		}
+		if err != nil && !strict && (key == RuleIsolation || key == PolicyEnvironment) {
+			warnings = append(warnings, fmt.Sprintf("policy %s is not evaluated: %v", key, err))
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid %s policy: %w", key, err)
+		}
+		if rule != nil {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules, warnings, nil
+}
+
+// allowedEnvironmentsRule restricts the environments a component may be planned in
+type allowedEnvironmentsRule struct {
+	patterns []string
+}
+
+func parseAllowedEnvironments(value interface{}) (Rule, error) {
+	patterns, err := stringList(value)
+	if err != nil {
+		return nil, err
+	}
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("bad pattern %q: %w", pattern, err)
+		}
+	}
+	return &allowedEnvironmentsRule{patterns: patterns}, nil
+}
+
+func (r *allowedEnvironmentsRule) Kind() string { return RuleAllowedEnvironments }
+
+func (r *allowedEnvironmentsRule) Check(inst *model.ComponentInstance) []string {
+	for _, pattern := range r.patterns {
+		if matched, _ := path.Match(pattern, inst.Environment); matched {
+			return nil
+		}
+	}
+	return []string{fmt.Sprintf("environment %q is not allowed (allowed: %s)", inst.Environment, strings.Join(r.patterns, ", "))}
+}
+
+// parseEnvironmentShorthand turns environment: <name>-only into an
+// allowedEnvironments rule for <name>
+func parseEnvironmentShorthand(value interface{}) (Rule, error) {
+	s, ok := value.(string)
+	name := strings.TrimSuffix(s, "-only")
+	if !ok || name == s || name == "" {
+		return nil, fmt.Errorf("expected <environment>-only, e.g. production-only, got %v", value)
+	}
+	return parseAllowedEnvironments(name)
+}
+
+// isolationRule keeps an instance's dependencies in its own environment
+type isolationRule struct{}
+
+func parseIsolation(value interface{}) (Rule, error) {
+	switch scalarString(value) {
+	case IsolationStrict:
+		return &isolationRule{}, nil
+	case IsolationNone:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("expected %s or %s, got %v", IsolationStrict, IsolationNone, value)
+	}
+}
+
+func (r *isolationRule) Kind() string { return RuleIsolation }
+
+func (r *isolationRule) Check(inst *model.ComponentInstance) []string {
+	var messages []string
+	for _, dep := range inst.DependsOn {
+		if dep.Scope == model.ScopeCrossEnvironment && dep.Environment != inst.Environment {
+			messages = append(messages, fmt.Sprintf("depends on %s@%s outside environment %q", dep.ComponentName, dep.Environment, inst.Environment))
+		}
+	}
+	return messages
+}
+
+// requiredLabelsRule requires labels to be present, optionally with a fixed value
+type requiredLabelsRule struct {
+	labels map[string]string // label -> required value ("" means any value)
+}
+
+func parseRequiredLabels(value interface{}) (Rule, error) {
+	labels := make(map[string]string)
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, expected := range v {
+			labels[name] = scalarString(expected)
+		}
+	default:
+		names, err := stringList(value)
+		if err != nil {
+			return nil, fmt.Errorf("expected a list of label names or a map of label values")
+		}
+		for _, name := range names {
+			labels[name] = ""
+		}
+	}
+	return &requiredLabelsRule{labels: labels}, nil
+}
+
+func (r *requiredLabelsRule) Kind() string { return RuleRequiredLabels }
+
+func (r *requiredLabelsRule) Check(inst *model.ComponentInstance) []string {
+	var messages []string
+	for _, name := range sortedKeys(r.labels) {
+		expected := r.labels[name]
+		actual, ok := inst.Labels[name]
+		switch {
+		case !ok:
+			messages = append(messages, fmt.Sprintf("missing required label %q", name))
+		case expected != "" && actual != expected:
+			messages = append(messages, fmt.Sprintf("label %q is %q, must be %q", name, actual, expected))
+		}
+	}
+	return messages
+}
+
+// forbiddenInputsRule rejects components that set any of the listed inputs
+type forbiddenInputsRule struct {
+	inputs []string
+}
+
+func parseForbiddenInputs(value interface{}) (Rule, error) {
+	inputs, err := stringList(value)
+	if err != nil {
+		return nil, err
+	}
+	return &forbiddenInputsRule{inputs: inputs}, nil
+}
+
+func (r *forbiddenInputsRule) Kind() string { return RuleForbiddenInputs }
+
+func (r *forbiddenInputsRule) Check(inst *model.ComponentInstance) []string {
+	var messages []string
+	for _, input := range r.inputs {
+		if _, ok := inst.Inputs[input]; ok {
+			messages = append(messages, fmt.Sprintf("input %q is forbidden", input))
+		}
+	}
+	return messages
+}
+
+// inputConstraintsRule constrains input values
+type inputConstraintsRule struct {
+	constraints map[string]valueConstraint
+}
+
+// valueConstraint is the set of checks applied to one input
+type valueConstraint struct {
+	required bool
+	min      *float64
+	max      *float64
+	enum     []string
+	pattern  *regexp.Regexp
+}
+
+func parseInputConstraints(value interface{}) (Rule, error) {
+	spec, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a map of input name to constraints")
+	}
+
+	rule := &inputConstraintsRule{constraints: make(map[string]valueConstraint)}
+	for input, raw := range spec {
+		fields, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("constraints for input %q must be a map", input)
+		}
+
+		var c valueConstraint
+		for field, fieldValue := range fields {
+			switch field {
+			case "required":
+				b, ok := fieldValue.(bool)
+				if !ok {
+					return nil, fmt.Errorf("input %q: required must be a boolean", input)
+				}
+				c.required = b
+			case "min", "max":
+				n, ok := toFloat(fieldValue)
+				if !ok {
+					return nil, fmt.Errorf("input %q: %s must be a number", input, field)
+				}
+				if field == "min" {
+					c.min = &n
+				} else {
+					c.max = &n
+				}
+			case "enum":
+				items, ok := fieldValue.([]interface{})
+				if !ok {
+					return nil, fmt.Errorf("input %q: enum must be a list", input)
+				}
+				for _, item := range items {
+					c.enum = append(c.enum, scalarString(item))
+				}
+			case "pattern":
+				s, ok := fieldValue.(string)
+				if !ok {
+					return nil, fmt.Errorf("input %q: pattern must be a string", input)
+				}
+				re, err := regexp.Compile(s)
+				if err != nil {
+					return nil, fmt.Errorf("input %q: bad pattern: %w", input, err)
+				}
+				c.pattern = re
+			default:
+				return nil, fmt.Errorf("input %q: unknown constraint %q (expected required, min, max, enum or pattern)", input, field)
+			}
+		}
+		rule.constraints[input] = c
+	}
+
+	return rule, nil
+}
+
+func (r *inputConstraintsRule) Kind() string { return RuleInputConstraints }
+
+func (r *inputConstraintsRule) Check(inst *model.ComponentInstance) []string {
+	var messages []string
+	for _, input := range sortedKeys(r.constraints) {
+		c := r.constraints[input]
+		value, ok := inst.Inputs[input]
+		if !ok {
+			if c.required {
+				messages = append(messages, fmt.Sprintf("input %q is required", input))
+			}
+			continue
+		}
+
+		if c.min != nil || c.max != nil {
+			n, ok := toFloat(value)
+			if !ok {
+				messages = append(messages, fmt.Sprintf("input %q is %v, must be a number", input, value))
+				continue
+			}
+			if c.min != nil && n < *c.min {
+				messages = append(messages, fmt.Sprintf("input %q is %v, must be >= %v", input, value, *c.min))
+			}
+			if c.max != nil && n > *c.max {
+				messages = append(messages, fmt.Sprintf("input %q is %v, must be <= %v", input, value, *c.max))
+			}
+		}
+
+		if len(c.enum) > 0 {
+			actual := scalarString(value)
+			allowed := false
+			for _, candidate := range c.enum {
+				if candidate == actual {
+					allowed = true
+					break
+				}
+			}
+			if !allowed {
+				messages = append(messages, fmt.Sprintf("input %q is %q, must be one of: %s", input, actual, strings.Join(c.enum, ", ")))
+			}
+		}
+
+		if c.pattern != nil {
+			actual := scalarString(value)
+			if !c.pattern.MatchString(actual) {
+				messages = append(messages, fmt.Sprintf("input %q is %q, must match %s", input, actual, c.pattern))
+			}
+		}
+	}
+	return messages
+}
+
+// knownPolicies lists every policy key ParseRules accepts
+func knownPolicies() []string {
+	keys := []string{
+		RuleAllowedEnvironments, RuleRequiredLabels, RuleForbiddenInputs,
+		RuleInputConstraints, RuleIsolation, PolicyEnvironment,
+	}
+	keys = append(keys, approvalKeys...)
+	sort.Strings(keys)
+	return keys
+}
+
+func stringList(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected a list of strings, got %v", item)
+			}
+			result = append(result, s)
+		}
+		return result, nil
+	case []string:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("expected a list of strings, got %T", value)
+	}
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(v, 64)
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func scalarString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,210 @@
+package render
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sourceplane/liteci/internal/model"
+)
+
+// compositionPalette colors diagram nodes by composition, assigned in
+// composition name order
+var compositionPalette = []string{
+	"#dbeafe", "#dcfce7", "#fef3c7", "#fce7f3",
+	"#e0e7ff", "#ccfbf1", "#fee2e2", "#f3e8ff",
+}
+
+// diagramStroke is the border color of every diagram node
+const diagramStroke = "#475569"
+
+// ViewMermaid returns the job DAG as a Mermaid flowchart: one subgraph per
+// environment, nodes colored by composition, gated jobs drawn as hexagons and
+// edges labelled with non-success dependency conditions
+func (pv *PlanViewer) ViewMermaid() string {
+	ids, envs, jobsByEnv := pv.diagramLayout()
+	colors := pv.compositionColors()
+
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+
+	for i, env := range envs {
+		sb.WriteString(fmt.Sprintf("  subgraph env%d[%s]\n", i, mermaidLabel(env)))
+		for _, job := range jobsByEnv[env] {
+			label := mermaidLabel(jobLabel(job, "<br/>"))
+			if len(job.Gates) > 0 {
+				sb.WriteString(fmt.Sprintf("    %s{{%s}}\n", ids[job.ID], label))
+			} else {
+				sb.WriteString(fmt.Sprintf("    %s(%s)\n", ids[job.ID], label))
+			}
+		}
+		sb.WriteString("  end\n")
+	}
+
+	for _, job := range pv.sortedJobs() {
+		for _, dep := range sortedDeps(job) {
+			depID, ok := ids[dep]
+			if !ok {
+				continue
+			}
+			switch cond := job.Conditions[dep]; cond {
+			case "", model.ConditionSuccess:
+				sb.WriteString(fmt.Sprintf("  %s --> %s\n", depID, ids[job.ID]))
+			default:
+				sb.WriteString(fmt.Sprintf("  %s -. %s .-> %s\n", depID, cond, ids[job.ID]))
+			}
+		}
+	}
+
+	compositions := sortedKeys(colors)
+	for i, composition := range compositions {
+		sb.WriteString(fmt.Sprintf("  classDef comp%d fill:%s,stroke:%s,color:#0f172a\n", i, colors[composition], diagramStroke))
+	}
+	for i, composition := range compositions {
+		var members []string
+		for _, job := range pv.sortedJobs() {
+			if job.Composition == composition {
+				members = append(members, ids[job.ID])
+			}
+		}
+		sb.WriteString(fmt.Sprintf("  class %s comp%d\n", strings.Join(members, ","), i))
+	}
+
+	return sb.String()
+}
+
+// ViewDOT returns the job DAG as a Graphviz DOT digraph: one cluster per
+// environment, nodes filled by composition, gated jobs drawn as hexagons and
+// edges labelled with non-success dependency conditions
+func (pv *PlanViewer) ViewDOT() string {
+	_, envs, jobsByEnv := pv.diagramLayout()
+	colors := pv.compositionColors()
+
+	var sb strings.Builder
+	name := pv.plan.Metadata.Name
+	if name == "" {
+		name = "plan"
+	}
+	sb.WriteString(fmt.Sprintf("digraph %s {\n", dotQuote(name)))
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString(fmt.Sprintf("  node [shape=box, style=\"rounded,filled\", fontname=\"Helvetica\", color=%s];\n", dotQuote(diagramStroke)))
+	sb.WriteString("  edge [fontname=\"Helvetica\", fontsize=10];\n")
+
+	for i, env := range envs {
+		sb.WriteString(fmt.Sprintf("\n  subgraph cluster_%d {\n", i))
+		sb.WriteString(fmt.Sprintf("    label=%s;\n", dotQuote(env)))
+		sb.WriteString("    style=\"rounded,dashed\";\n")
+		for _, job := range jobsByEnv[env] {
+			attrs := []string{
+				"label=" + dotQuote(jobLabel(job, "\n")),
+				"fillcolor=" + dotQuote(colors[job.Composition]),
+				"tooltip=" + dotQuote(job.ID),
+			}
+			if len(job.Gates) > 0 {
+				attrs = append(attrs, "shape=hexagon")
+			}
+			sb.WriteString(fmt.Sprintf("    %s [%s];\n", dotQuote(job.ID), strings.Join(attrs, ", ")))
+		}
+		sb.WriteString("  }\n")
+	}
+
+	sb.WriteString("\n")
+	for _, job := range pv.sortedJobs() {
+		for _, dep := range sortedDeps(job) {
+			switch cond := job.Conditions[dep]; cond {
+			case "", model.ConditionSuccess:
+				sb.WriteString(fmt.Sprintf("  %s -> %s;\n", dotQuote(dep), dotQuote(job.ID)))
+			default:
+				sb.WriteString(fmt.Sprintf("  %s -> %s [style=dashed, label=%s];\n", dotQuote(dep), dotQuote(job.ID), dotQuote(cond)))
+			}
+		}
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// diagramLayout assigns short node IDs (n0, n1, ...) in job ID order and
+// groups the jobs by environment, with environments sorted by name
+func (pv *PlanViewer) diagramLayout() (map[string]string, []string, map[string][]*model.PlanJob) {
+	ids := make(map[string]string, len(pv.plan.Jobs))
+	jobsByEnv := make(map[string][]*model.PlanJob)
+	for i, job := range pv.sortedJobs() {
+		ids[job.ID] = fmt.Sprintf("n%d", i)
+		jobsByEnv[job.Environment] = append(jobsByEnv[job.Environment], job)
+	}
+
+	envs := make([]string, 0, len(jobsByEnv))
+	for env := range jobsByEnv {
+		envs = append(envs, env)
+	}
+	sort.Strings(envs)
+
+	return ids, envs, jobsByEnv
+}
+
+// compositionColors maps every composition in the plan to a palette color
+func (pv *PlanViewer) compositionColors() map[string]string {
+	colors := make(map[string]string)
+	for _, job := range pv.plan.Jobs {
+		colors[job.Composition] = ""
+	}
+	for i, composition := range sortedKeys(colors) {
+		colors[composition] = compositionPalette[i%len(compositionPalette)]
+	}
+	return colors
+}
+
+// sortedJobs returns the plan's jobs ordered by ID
+func (pv *PlanViewer) sortedJobs() []*model.PlanJob {
+	jobs := make([]*model.PlanJob, len(pv.plan.Jobs))
+	for i := range pv.plan.Jobs {
+		jobs[i] = &pv.plan.Jobs[i]
+	}
+	sort.Slice(jobs, func(a, b int) bool {
+		return jobs[a].ID < jobs[b].ID
+	})
+	return jobs
+}
+
+func sortedDeps(job *model.PlanJob) []string {
+	deps := append([]string(nil), job.DependsOn...)
+	sort.Strings(deps)
+	return deps
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// jobLabel describes a job node: component, then job name and composition,
+// then the gate when there is one
+func jobLabel(job *model.PlanJob, newline string) string {
+	label := job.Component + newline + job.Name
+	if job.Composition != "" {
+		label += " · " + job.Composition
+	}
+	if len(job.Gates) > 0 {
+		label += newline + "⏸ " + job.Gates[0].Type
+	}
+	return label
+}
+
+// mermaidLabel quotes a Mermaid node label, escaping double quotes
+func mermaidLabel(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
+
+// dotQuote quotes a DOT ID, escaping backslashes and double quotes and
+// turning newlines into centered line breaks
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
@@ -0,0 +1,49 @@
+package render
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/sourceplane/liteci/internal/model"
+)
+
+// ContentDigest returns the sha256 of a plan's canonical JSON, computed with
+// metadata.digest empty so the digest can be stored in the plan itself
+func ContentDigest(plan *model.Plan) (string, error) {
+	unsealed := *plan
+	unsealed.Metadata.Digest = ""
+
+	data, err := NewRenderer().RenderCanonicalJSON(&unsealed)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode plan: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// StampDigest records the plan's content digest in metadata.digest
+func StampDigest(plan *model.Plan) error {
+	digest, err := ContentDigest(plan)
+	if err != nil {
+		return err
+	}
+	plan.Metadata.Digest = digest
+	return nil
+}
+
+// VerifyDigest checks that a plan still matches the content digest recorded
+// when it was generated
+func VerifyDigest(plan *model.Plan) error {
+	if plan.Metadata.Digest == "" {
+		return fmt.Errorf("plan has no metadata.digest")
+	}
+	digest, err := ContentDigest(plan)
+	if err != nil {
+		return err
+	}
+	if digest != plan.Metadata.Digest {
+		return fmt.Errorf("plan content does not match its digest (recorded %s, computed %s); it was modified after liteci plan generated it", plan.Metadata.Digest, digest)
+	}
+	return nil
+}
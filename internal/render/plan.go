@@ -1,8 +1,10 @@
 package render
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
@@ -34,7 +36,7 @@ func (r *Renderer) RenderPlanWithOrder(metadata model.Metadata, jobInstances map
 	plan := &model.Plan{
 		APIVersion: "sourceplane.io/v1",
 		Kind:       "Workflow",
-		Metadata: model.Metadata{
+		Metadata: model.PlanMetadata{
 			Name:        metadata.Name,
 			Description: metadata.Description,
 		},
@@ -68,6 +70,8 @@ func (r *Renderer) RenderPlanWithOrder(metadata model.Metadata, jobInstances map
 			Path:        job.Path,
 			Steps:       r.convertSteps(job.Steps),
 			DependsOn:   job.DependsOn,
+			Conditions:  job.Conditions,
+			Gates:       job.Gates,
 			Timeout:     job.Timeout,
 			Retries:     job.Retries,
 			Env:         job.Config, // Single source: Config
@@ -96,6 +100,13 @@ func (r *Renderer) convertSteps(steps []model.RenderedStep) []model.PlanStep {
 	return planSteps
 }
 
+// Plan serialization formats
+const (
+	FormatJSON          = "json"
+	FormatYAML          = "yaml"
+	FormatCanonicalJSON = "canonical-json" // Sorted keys, no HTML escaping, trailing newline
+)
+
 // RenderJSON renders plan as JSON
 func (r *Renderer) RenderJSON(plan *model.Plan) ([]byte, error) {
 	return json.MarshalIndent(plan, "", "  ")
@@ -106,35 +117,78 @@ func (r *Renderer) RenderYAML(plan *model.Plan) ([]byte, error) {
 	return yaml.Marshal(plan)
 }
 
-// WritePlan writes plan to file (JSON or YAML based on extension)
-func (r *Renderer) WritePlan(plan *model.Plan, path string) error {
-	var data []byte
-	var err error
+// RenderCanonicalJSON renders plan as byte-stable JSON: every object's keys
+// are sorted, HTML characters are not escaped and output ends with a newline,
+// so plans committed to a repository produce clean diffs
+func (r *Renderer) RenderCanonicalJSON(plan *model.Plan) ([]byte, error) {
+	data, err := json.Marshal(plan)
+	if err != nil {
+		return nil, err
+	}
 
-	// Ensure directory exists
-	dir := filepath.Dir(path)
-	if dir != "." && dir != "" {
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("failed to create directory: %w", err)
-		}
+	// Round-trip through generic values so struct fields are emitted in key
+	// order too; UseNumber keeps numbers exactly as encoded
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
 	}
 
-	// Determine format from extension
-	ext := filepath.Ext(path)
-	switch ext {
-	case ".json":
-		data, err = r.RenderJSON(plan)
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(generic); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Render serializes plan in the given format
+func (r *Renderer) Render(plan *model.Plan, format string) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		return r.RenderJSON(plan)
+	case FormatYAML, "yml":
+		return r.RenderYAML(plan)
+	case FormatCanonicalJSON:
+		return r.RenderCanonicalJSON(plan)
+	default:
+		return nil, fmt.Errorf("unsupported format %q (expected %s, %s or %s)", format, FormatJSON, FormatYAML, FormatCanonicalJSON)
+	}
+}
+
+// FormatForPath picks the format from a file extension, defaulting to JSON
+func FormatForPath(path string) string {
+	switch filepath.Ext(path) {
 	case ".yaml", ".yml":
-		data, err = r.RenderYAML(plan)
+		return FormatYAML
 	default:
-		// Default to JSON if no extension
-		data, err = r.RenderJSON(plan)
+		return FormatJSON
 	}
+}
 
+// WritePlan writes plan to a file. An empty format is picked from the file
+// extension (JSON or YAML).
+func (r *Renderer) WritePlan(plan *model.Plan, path, format string) error {
+	if format == "" {
+		format = FormatForPath(path)
+	}
+
+	data, err := r.Render(plan, format)
 	if err != nil {
 		return fmt.Errorf("failed to render plan: %w", err)
 	}
 
+	// Ensure directory exists
+	dir := filepath.Dir(path)
+	if dir != "." && dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+
 	// Write to file
 	if err := os.WriteFile(path, data, 0644); err != nil {
 		return fmt.Errorf("failed to write plan to %s: %w", path, err)
@@ -143,6 +197,24 @@ func (r *Renderer) WritePlan(plan *model.Plan, path string) error {
 	return nil
 }
 
+// WritePlanTo writes plan to w, e.g. stdout. An empty format means JSON.
+func (r *Renderer) WritePlanTo(w io.Writer, plan *model.Plan, format string) error {
+	if format == "" {
+		format = FormatJSON
+	}
+
+	data, err := r.Render(plan, format)
+	if err != nil {
+		return fmt.Errorf("failed to render plan: %w", err)
+	}
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		data = append(data, '\n')
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
 // DebugDump outputs debug information about the plan
 func (r *Renderer) DebugDump(plan *model.Plan) string {
 	output := fmt.Sprintf("Plan: %s (%s)\n", plan.Metadata.Name, plan.Metadata.Description)
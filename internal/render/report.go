@@ -0,0 +1,378 @@
+package render
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sourceplane/liteci/internal/model"
+)
+
+//go:embed templates/report.html
+var reportTemplate string
+
+// Reasons a component instance is part of a plan
+const (
+	InclusionSelected   = "selected"   // Matched by its environment's selectors, with no planned dependencies or dependents
+	InclusionChanged    = "changed"    // Its files or the intent changed (--changed)
+	InclusionDependency = "dependency" // Needed by another planned (with --changed: changed) component
+	InclusionDependent  = "dependent"  // Depends on another planned (with --changed: changed) component
+)
+
+// Layout of the report's DAG, in SVG user units
+const (
+	reportNodeWidth  = 220
+	reportNodeHeight = 46
+	reportColumnGap  = 70
+	reportRowGap     = 16
+	reportMargin     = 20
+)
+
+// PlanReport renders a plan as a self-contained HTML page: the job DAG plus
+// every job's steps, merged inputs, labels, policies and the reason its
+// component was planned
+type PlanReport struct {
+	plan      *model.Plan
+	instances map[string]*model.ComponentInstance // Keyed by component@environment
+	inclusion map[string]string                   // Component -> inclusion reason
+}
+
+// NewPlanReport creates a report for a plan from the component instances it
+// was planned from
+func NewPlanReport(plan *model.Plan, instances map[string][]*model.ComponentInstance) *PlanReport {
+	indexed := make(map[string]*model.ComponentInstance)
+	for _, envInstances := range instances {
+		for _, inst := range envInstances {
+			indexed[inst.ComponentName+"@"+inst.Environment] = inst
+		}
+	}
+	return &PlanReport{
+		plan:      plan,
+		instances: indexed,
+		inclusion: make(map[string]string),
+	}
+}
+
+// SetInclusion records why a component is part of the plan, overriding the
+// reason derived from its selectors
+func (r *PlanReport) SetInclusion(component, reason string) {
+	r.inclusion[component] = reason
+}
+
+// Write renders the report to path
+func (r *PlanReport) Write(path string) error {
+	data, err := r.Render()
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	if dir != "." && dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write report to %s: %w", path, err)
+	}
+	return nil
+}
+
+// Render returns the report HTML
+func (r *PlanReport) Render() ([]byte, error) {
+	tmpl, err := template.New("report").Funcs(template.FuncMap{
+		"join": strings.Join,
+	}).Parse(reportTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse report template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, r.data()); err != nil {
+		return nil, fmt.Errorf("failed to render report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+type reportData struct {
+	Plan         *model.Plan
+	Environments []string
+	Components   int
+	Compositions []reportComposition
+	Graph        reportGraph
+	Jobs         []reportJob
+}
+
+type reportComposition struct {
+	Name  string
+	Color string
+}
+
+type reportGraph struct {
+	Width      int
+	Height     int
+	NodeWidth  int
+	NodeHeight int
+	Nodes      []reportNode
+	Edges      []reportEdge
+	// Links lists each job's dependencies by node index, for the page script
+	Links [][]int
+}
+
+type reportNode struct {
+	Index  int
+	ID     string
+	Title  string
+	Detail string
+	X, Y   int
+	Color  string
+	Gated  bool
+}
+
+type reportEdge struct {
+	From, To  int
+	Path      string
+	Condition string // Empty for success
+}
+
+type reportJob struct {
+	Index      int
+	Job        *model.PlanJob
+	Color      string
+	Inclusion  string
+	SelectedBy []string
+	DependsOn  []reportLink
+	Dependents []reportLink
+	Inputs     []reportValue
+	Labels     []reportValue
+	Policies   []reportValue
+}
+
+type reportLink struct {
+	Index     int
+	ID        string
+	Condition string
+}
+
+type reportValue struct {
+	Key   string
+	Value string
+}
+
+func (r *PlanReport) data() reportData {
+	viewer := NewPlanViewer(r.plan)
+	colors := viewer.compositionColors()
+	jobs := viewer.sortedJobs()
+
+	index := make(map[string]int, len(jobs))
+	for i, job := range jobs {
+		index[job.ID] = i
+	}
+
+	data := reportData{Plan: r.plan}
+	for _, name := range sortedKeys(colors) {
+		data.Compositions = append(data.Compositions, reportComposition{Name: name, Color: colors[name]})
+	}
+
+	components := make(map[string]bool)
+	environments := make(map[string]bool)
+	dependents := make(map[string][]reportLink)
+	for _, job := range jobs {
+		components[job.Component] = true
+		environments[job.Environment] = true
+		for _, dep := range sortedDeps(job) {
+			dependents[dep] = append(dependents[dep], reportLink{Index: index[job.ID], ID: job.ID, Condition: job.Conditions[dep]})
+		}
+	}
+	data.Components = len(components)
+	for env := range environments {
+		data.Environments = append(data.Environments, env)
+	}
+	sort.Strings(data.Environments)
+
+	for i, job := range jobs {
+		rj := reportJob{
+			Index:      i,
+			Job:        job,
+			Color:      colors[job.Composition],
+			Dependents: dependents[job.ID],
+			Inputs:     reportValues(job.Config),
+			Labels:     reportStringValues(job.Labels),
+		}
+		for _, dep := range sortedDeps(job) {
+			if depIndex, ok := index[dep]; ok {
+				rj.DependsOn = append(rj.DependsOn, reportLink{Index: depIndex, ID: dep, Condition: job.Conditions[dep]})
+			}
+		}
+		inst := r.instances[job.Component+"@"+job.Environment]
+		if inst != nil {
+			rj.SelectedBy = inst.SelectedBy
+			rj.Policies = reportValues(inst.Policies)
+		}
+		rj.Inclusion = r.inclusionOf(job.Component, inst)
+		data.Jobs = append(data.Jobs, rj)
+	}
+
+	data.Graph = layoutGraph(jobs, index, colors)
+	return data
+}
+
+// inclusionOf returns the recorded inclusion reason of a component, falling
+// back to its instance's place in the resolved dependencies: needed by another
+// planned instance, depending on one, or neither
+func (r *PlanReport) inclusionOf(component string, inst *model.ComponentInstance) string {
+	if reason, ok := r.inclusion[component]; ok {
+		return reason
+	}
+	switch {
+	case inst == nil:
+		return InclusionSelected
+	case len(inst.RequiredBy) > 0:
+		return InclusionDependency
+	case len(inst.DependsOn) > 0:
+		return InclusionDependent
+	}
+	return InclusionSelected
+}
+
+// layoutGraph places jobs in columns by their longest dependency chain, so
+// every edge points right, and sorts each column by environment then job ID
+func layoutGraph(jobs []*model.PlanJob, index map[string]int, colors map[string]string) reportGraph {
+	depth := make([]int, len(jobs))
+	var depthOf func(i int, visiting map[int]bool) int
+	depthOf = func(i int, visiting map[int]bool) int {
+		if depth[i] > 0 || visiting[i] {
+			return depth[i]
+		}
+		visiting[i] = true
+		d := 0
+		for _, dep := range jobs[i].DependsOn {
+			if j, ok := index[dep]; ok {
+				if dd := depthOf(j, visiting) + 1; dd > d {
+					d = dd
+				}
+			}
+		}
+		depth[i] = d
+		return d
+	}
+	for i := range jobs {
+		depthOf(i, make(map[int]bool))
+	}
+
+	columns := make(map[int][]int)
+	maxDepth := 0
+	for i := range jobs {
+		columns[depth[i]] = append(columns[depth[i]], i)
+		if depth[i] > maxDepth {
+			maxDepth = depth[i]
+		}
+	}
+
+	graph := reportGraph{
+		NodeWidth:  reportNodeWidth,
+		NodeHeight: reportNodeHeight,
+		Nodes:      make([]reportNode, len(jobs)),
+		Links:      make([][]int, len(jobs)),
+	}
+	maxRows := 0
+	for col := 0; col <= maxDepth; col++ {
+		members := columns[col]
+		sort.SliceStable(members, func(a, b int) bool {
+			ja, jb := jobs[members[a]], jobs[members[b]]
+			if ja.Environment != jb.Environment {
+				return ja.Environment < jb.Environment
+			}
+			return ja.ID < jb.ID
+		})
+		for row, i := range members {
+			job := jobs[i]
+			graph.Nodes[i] = reportNode{
+				Index:  i,
+				ID:     job.ID,
+				Title:  job.Component,
+				Detail: job.Name + " · " + job.Environment,
+				X:      reportMargin + col*(reportNodeWidth+reportColumnGap),
+				Y:      reportMargin + row*(reportNodeHeight+reportRowGap),
+				Color:  colors[job.Composition],
+				Gated:  len(job.Gates) > 0,
+			}
+		}
+		if len(members) > maxRows {
+			maxRows = len(members)
+		}
+	}
+
+	for i, job := range jobs {
+		graph.Links[i] = []int{}
+		for _, dep := range sortedDeps(job) {
+			j, ok := index[dep]
+			if !ok {
+				continue
+			}
+			graph.Links[i] = append(graph.Links[i], j)
+			from, to := graph.Nodes[j], graph.Nodes[i]
+			x1, y1 := from.X+reportNodeWidth, from.Y+reportNodeHeight/2
+			x2, y2 := to.X, to.Y+reportNodeHeight/2
+			mid := (x1 + x2) / 2
+			condition := job.Conditions[dep]
+			if condition == model.ConditionSuccess {
+				condition = ""
+			}
+			graph.Edges = append(graph.Edges, reportEdge{
+				From:      j,
+				To:        i,
+				Path:      fmt.Sprintf("M%d,%d C%d,%d %d,%d %d,%d", x1, y1, mid, y1, mid, y2, x2, y2),
+				Condition: condition,
+			})
+		}
+	}
+
+	graph.Width = 2*reportMargin + (maxDepth+1)*reportNodeWidth + maxDepth*reportColumnGap
+	graph.Height = 2*reportMargin + maxRows*reportNodeHeight + max(maxRows-1, 0)*reportRowGap
+	return graph
+}
+
+// reportValues lists a map's entries sorted by key; maps and lists are shown
+// as indented JSON
+func reportValues(values map[string]interface{}) []reportValue {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make([]reportValue, 0, len(keys))
+	for _, key := range keys {
+		var value string
+		switch v := values[key].(type) {
+		case string:
+			value = v
+		case map[string]interface{}, []interface{}:
+			data, err := json.MarshalIndent(v, "", "  ")
+			if err != nil {
+				value = fmt.Sprintf("%v", v)
+			} else {
+				value = string(data)
+			}
+		default:
+			value = fmt.Sprintf("%v", v)
+		}
+		result = append(result, reportValue{Key: key, Value: value})
+	}
+	return result
+}
+
+func reportStringValues(values map[string]string) []reportValue {
+	result := make([]reportValue, 0, len(values))
+	for _, key := range sortedKeys(values) {
+		result = append(result, reportValue{Key: key, Value: values[key]})
+	}
+	return result
+}
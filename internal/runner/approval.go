@@ -0,0 +1,271 @@
+package runner
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sourceplane/liteci/internal/model"
+	"github.com/sourceplane/liteci/internal/render"
+)
+
+// DefaultApprovalPoll is how often a waiting run re-reads the approvals file
+const DefaultApprovalPoll = 2 * time.Second
+
+// Approval records who unlocked a job's gates and how
+type Approval struct {
+	ApprovedBy string    `json:"approvedBy"`
+	ApprovedAt time.Time `json:"approvedAt"`
+	Via        string    `json:"via"` // file, prompt or dry-run
+}
+
+// Approvals is the approvals file written by `liteci approve` and read by a
+// waiting run. It is bound to a plan digest so approvals for one plan never
+// unlock another.
+type Approvals struct {
+	APIVersion string               `json:"apiVersion"`
+	Kind       string               `json:"kind"`
+	PlanDigest string               `json:"planDigest"`
+	Jobs       map[string]*Approval `json:"jobs"`
+}
+
+// DefaultApprovalsPath returns the approvals file that sits next to a plan
+// file, e.g. plan.json -> plan.approvals.json
+func DefaultApprovalsPath(planPath string) string {
+	ext := filepath.Ext(planPath)
+	return strings.TrimSuffix(planPath, ext) + ".approvals.json"
+}
+
+// LoadApprovals reads an approvals file; a missing file holds no approvals
+func LoadApprovals(path string) (*Approvals, error) {
+	approvals := &Approvals{
+		APIVersion: "sourceplane.io/v1",
+		Kind:       "Approvals",
+		Jobs:       make(map[string]*Approval),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return approvals, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read approvals %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, approvals); err != nil {
+		return nil, fmt.Errorf("failed to parse approvals %s: %w", path, err)
+	}
+	if approvals.Jobs == nil {
+		approvals.Jobs = make(map[string]*Approval)
+	}
+
+	return approvals, nil
+}
+
+// Save atomically writes the approvals file to path
+func (a *Approvals) Save(path string) error {
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to render approvals: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write approvals to %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write approvals to %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// Approve records an approval for each gated job of plan in the approvals
+// file at path. Approvals written for a different plan are discarded.
+func Approve(path string, plan *model.Plan, jobIDs []string, approvedBy string) error {
+	digest, err := render.ContentDigest(plan)
+	if err != nil {
+		return err
+	}
+
+	gated := make(map[string]bool)
+	for _, job := range plan.Jobs {
+		if len(job.Gates) > 0 {
+			gated[job.ID] = true
+		}
+	}
+	for _, jobID := range jobIDs {
+		if !gated[jobID] {
+			return fmt.Errorf("job %s has no gates in the plan", jobID)
+		}
+	}
+
+	approvals, err := LoadApprovals(path)
+	if err != nil {
+		return err
+	}
+	if approvals.PlanDigest != digest {
+		approvals.PlanDigest = digest
+		approvals.Jobs = make(map[string]*Approval)
+	}
+
+	now := time.Now().UTC()
+	for _, jobID := range jobIDs {
+		approvals.Jobs[jobID] = &Approval{ApprovedBy: approvedBy, ApprovedAt: now, Via: "file"}
+	}
+
+	return approvals.Save(path)
+}
+
+// ApprovalDeniedError is returned for a gated job whose approval was refused
+// or did not arrive in time
+type ApprovalDeniedError struct {
+	JobID  string
+	Reason string
+}
+
+func (e *ApprovalDeniedError) Error() string {
+	return fmt.Sprintf("job %s was not approved: %s", e.JobID, e.Reason)
+}
+
+// awaitApproval blocks until every gate of job is unlocked. In dry-run mode
+// gates are only reported. Otherwise the approvals file is polled until the job
+// is approved, ApprovalTimeout elapses or ctx is cancelled; with Prompt set the
+// operator is also asked on the terminal, and whichever answers first wins.
+func (r *Runner) awaitApproval(ctx context.Context, job model.PlanJob, digest string) (*Approval, error) {
+	reasons := make([]string, 0, len(job.Gates))
+	for _, gate := range job.Gates {
+		reasons = append(reasons, gate.Reason)
+	}
+	reason := strings.Join(reasons, "; ")
+
+	if r.DryRun {
+		r.printf("⏸ Job %s requires approval (%s); not waiting in dry-run\n", job.ID, reason)
+		return &Approval{ApprovedAt: time.Now().UTC(), Via: "dry-run"}, nil
+	}
+
+	if approval := r.fileApproval(job.ID, digest); approval != nil {
+		return approval, nil
+	}
+
+	if r.ApprovalTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.ApprovalTimeout)
+		defer cancel()
+	}
+
+	r.printf("⏸ Job %s is waiting for approval (%s); run `liteci approve %s`\n", job.ID, reason, job.ID)
+
+	poll := r.ApprovalPoll
+	if poll <= 0 {
+		poll = DefaultApprovalPoll
+	}
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	// Prompts are asked one at a time: a gate waits for the prompt turn while
+	// still polling the approvals file, and gives the turn back when done.
+	var turn chan struct{}
+	var answers <-chan string
+	if r.Prompt != nil {
+		r.startPrompt()
+		turn = r.promptTurn
+	}
+	prompting := false
+	defer func() {
+		if prompting {
+			r.promptTurn <- struct{}{}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if prompting {
+				r.printf("\n")
+			}
+			return nil, r.approvalTimedOut(ctx, job)
+		case <-ticker.C:
+			if approval := r.fileApproval(job.ID, digest); approval != nil {
+				if prompting {
+					r.printf("\n✓ Job %s was approved in %s\n", job.ID, r.ApprovalsPath)
+				}
+				return approval, nil
+			}
+		case <-turn:
+			turn = nil
+			prompting = true
+			answers = r.promptLines
+			r.printf("? Approve job %s (%s)? [y/N] ", job.ID, reason)
+		case answer, ok := <-answers:
+			if !ok {
+				// The terminal closed; keep waiting on the approvals file
+				answers = nil
+				r.printf("\n")
+				continue
+			}
+			if answer != "y" && answer != "yes" {
+				return nil, &ApprovalDeniedError{JobID: job.ID, Reason: "denied at prompt"}
+			}
+			return &Approval{ApprovedBy: currentUser(), ApprovedAt: time.Now().UTC(), Via: "prompt"}, nil
+		}
+	}
+}
+
+// fileApproval returns the approval recorded for jobID in the approvals file,
+// ignoring approvals written for another plan
+func (r *Runner) fileApproval(jobID, digest string) *Approval {
+	if r.ApprovalsPath == "" {
+		return nil
+	}
+	approvals, err := LoadApprovals(r.ApprovalsPath)
+	if err != nil {
+		r.printf("⚠ %v\n", err)
+		return nil
+	}
+	if approvals.PlanDigest != digest {
+		return nil
+	}
+	return approvals.Jobs[jobID]
+}
+
+// startPrompt starts the one goroutine that reads answers from Prompt for the
+// lifetime of the runner. Reading from a single goroutine means a prompt that
+// is abandoned on timeout neither races with nor swallows the next answer.
+func (r *Runner) startPrompt() {
+	r.promptOnce.Do(func() {
+		r.promptTurn = make(chan struct{}, 1)
+		r.promptTurn <- struct{}{}
+		r.promptLines = make(chan string)
+
+		go func() {
+			defer close(r.promptLines)
+			scanner := bufio.NewScanner(r.Prompt)
+			for scanner.Scan() {
+				r.promptLines <- strings.ToLower(strings.TrimSpace(scanner.Text()))
+			}
+		}()
+	})
+}
+
+func (r *Runner) approvalTimedOut(ctx context.Context, job model.PlanJob) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return &ApprovalDeniedError{JobID: job.ID, Reason: fmt.Sprintf("no approval within %s", r.ApprovalTimeout)}
+	}
+	return ctx.Err()
+}
+
+// currentUser names the operator answering an approval prompt
+func currentUser() string {
+	for _, key := range []string{"LITECI_USER", "USER", "USERNAME"} {
+		if name := os.Getenv(key); name != "" {
+			return name
+		}
+	}
+	return "unknown"
+}
@@ -0,0 +1,49 @@
+package runner
+
+import (
+	"fmt"
+
+	"github.com/sourceplane/liteci/internal/model"
+)
+
+// edgeCondition returns the condition on the edge from job to dep,
+// defaulting to success for plans that predate conditions.
+func edgeCondition(job model.PlanJob, dep string) string {
+	if condition, ok := job.Conditions[dep]; ok && condition != "" {
+		return condition
+	}
+	return model.ConditionSuccess
+}
+
+// unmetCondition checks every dependency edge of job against the finished
+// dependency results and returns why the job must be skipped, or "" if it
+// may run.
+func unmetCondition(job model.PlanJob, results map[string]JobResult) string {
+	for _, dep := range job.DependsOn {
+		status := results[dep].Status
+		switch edgeCondition(job, dep) {
+		case model.ConditionAlways:
+			continue
+		case model.ConditionFailure:
+			if status != JobFailed {
+				return fmt.Sprintf("dependency %s %s (condition: failure)", dep, status)
+			}
+		default:
+			if !status.Succeeded() {
+				return fmt.Sprintf("dependency %s %s (condition: success)", dep, status)
+			}
+		}
+	}
+	return ""
+}
+
+// hasConditionalEdge reports whether job is reached through an always or
+// failure edge, which keeps it eligible to run after the run has stopped.
+func hasConditionalEdge(job model.PlanJob) bool {
+	for _, dep := range job.DependsOn {
+		if edgeCondition(job, dep) != model.ConditionSuccess {
+			return true
+		}
+	}
+	return false
+}
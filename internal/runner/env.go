@@ -0,0 +1,211 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/sourceplane/liteci/internal/model"
+)
+
+// Environment variable naming used for step processes.
+//
+// Every merged input (PlanJob.Config overlaid with PlanJob.Env) is exported as
+// LITECI_INPUT_<NAME>, where NAME is the input key converted to upper snake
+// case (replicaCount → REPLICA_COUNT). Nested values are flattened by joining
+// path segments with "_": map keys are converted the same way and list items
+// use their index (ingress.hosts[0] → LITECI_INPUT_INGRESS_HOSTS_0). Maps and
+// lists are additionally exported as compact JSON under their own name. Two
+// inputs that flatten to the same name (replicaCount and replica_count, or
+// a.b and a_b) are an error rather than one silently overwriting the other.
+const (
+	EnvInputPrefix = "LITECI_INPUT_"
+
+	EnvJobID       = "LITECI_JOB_ID"
+	EnvJobName     = "LITECI_JOB_NAME"
+	EnvComponent   = "LITECI_COMPONENT"
+	EnvEnvironment = "LITECI_ENVIRONMENT"
+	EnvComposition = "LITECI_COMPOSITION"
+	EnvStep        = "LITECI_STEP"
+)
+
+// stepEnvironment builds the environment of a step process: the parent
+// environment, then the job inputs, then the built-in LITECI_* variables.
+func stepEnvironment(job model.PlanJob, step model.PlanStep) ([]string, error) {
+	inputs, err := inputEnvironment(job)
+	if err != nil {
+		return nil, err
+	}
+
+	env := os.Environ()
+	env = append(env, inputs...)
+	env = append(env,
+		EnvJobID+"="+job.ID,
+		EnvJobName+"="+job.Name,
+		EnvComponent+"="+job.Component,
+		EnvEnvironment+"="+job.Environment,
+		EnvComposition+"="+job.Composition,
+		EnvStep+"="+step.Name,
+	)
+	return env, nil
+}
+
+// JobEnvironment returns the variables every step of job sees besides the
+// parent environment and LITECI_STEP: the flattened inputs and the built-in
+// LITECI_* variables. Exporters use it so steps of an exported pipeline see the
+// same variables as under liteci run.
+func JobEnvironment(job model.PlanJob) (map[string]string, error) {
+	vars, err := inputVars(job)
+	if err != nil {
+		return nil, err
+	}
+	vars[EnvJobID] = job.ID
+	vars[EnvJobName] = job.Name
+	vars[EnvComponent] = job.Component
+	vars[EnvEnvironment] = job.Environment
+	vars[EnvComposition] = job.Composition
+	return vars, nil
+}
+
+// InputVariable returns the variable a top-level input is exported as,
+// e.g. replicaCount → LITECI_INPUT_REPLICA_COUNT
+func InputVariable(key string) string {
+	return EnvInputPrefix + envName(key)
+}
+
+// inputEnvironment flattens the merged job inputs into sorted KEY=value pairs.
+func inputEnvironment(job model.PlanJob) ([]string, error) {
+	vars, err := inputVars(job)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		result = append(result, name+"="+vars[name])
+	}
+	return result, nil
+}
+
+// inputVars flattens the merged job inputs into LITECI_INPUT_* variables.
+func inputVars(job model.PlanJob) (map[string]string, error) {
+	inputs := make(map[string]interface{}, len(job.Config)+len(job.Env))
+	for k, v := range job.Config {
+		inputs[k] = v
+	}
+	for k, v := range job.Env {
+		inputs[k] = v
+	}
+
+	vars := make(map[string]string)
+	sources := make(map[string]string)
+	for _, k := range sortedInputKeys(inputs) {
+		if err := flattenEnv(vars, sources, InputVariable(k), k, inputs[k]); err != nil {
+			return nil, err
+		}
+	}
+	return vars, nil
+}
+
+// flattenEnv adds name=value for v, recursing into maps and lists in sorted
+// order. sources records the input path each variable came from so that two
+// paths flattening to the same name are reported instead of overwriting.
+func flattenEnv(vars, sources map[string]string, name, path string, v interface{}) error {
+	if prev, ok := sources[name]; ok {
+		return fmt.Errorf("inputs %s and %s both map to environment variable %s", prev, path, name)
+	}
+	sources[name] = path
+
+	switch val := v.(type) {
+	case map[string]interface{}:
+		vars[name] = jsonValue(val)
+		for _, k := range sortedInputKeys(val) {
+			if err := flattenEnv(vars, sources, name+"_"+envName(k), path+"."+k, val[k]); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		vars[name] = jsonValue(val)
+		for i, child := range val {
+			if err := flattenEnv(vars, sources, name+"_"+strconv.Itoa(i), fmt.Sprintf("%s[%d]", path, i), child); err != nil {
+				return err
+			}
+		}
+	default:
+		vars[name] = scalarValue(val)
+	}
+	return nil
+}
+
+func sortedInputKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func scalarValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case json.Number:
+		// Plans decoded with UseNumber keep integers beyond 2^53 exact
+		return val.String()
+	case int:
+		return strconv.Itoa(val)
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case uint64:
+		return strconv.FormatUint(val, 10)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(val), 'f', -1, 32)
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+func jsonValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+// envName converts an input key to an upper snake case variable name segment.
+func envName(key string) string {
+	var sb strings.Builder
+	runes := []rune(key)
+	for i, r := range runes {
+		switch {
+		case unicode.IsUpper(r):
+			// Split camelCase boundaries: fooBar → FOO_BAR, HTTPPort → HTTP_PORT
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				sb.WriteByte('_')
+			}
+			sb.WriteRune(r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			sb.WriteRune(unicode.ToUpper(r))
+		default:
+			sb.WriteByte('_')
+		}
+	}
+	return sb.String()
+}
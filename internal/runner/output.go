@@ -0,0 +1,63 @@
+package runner
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// prefixWriter prefixes every line written to it and emits whole lines only,
+// so output from concurrently running jobs never interleaves mid-line.
+type prefixWriter struct {
+	out    io.Writer
+	mu     *sync.Mutex // shared by all writers targeting the same stream set
+	prefix string
+	buf    []byte
+}
+
+func newPrefixWriter(out io.Writer, mu *sync.Mutex, prefix string) *prefixWriter {
+	return &prefixWriter{
+		out:    out,
+		mu:     mu,
+		prefix: prefix,
+	}
+}
+
+// Write buffers p and flushes every complete line with the writer's prefix.
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+
+	for {
+		idx := bytes.IndexByte(w.buf, '\n')
+		if idx < 0 {
+			break
+		}
+		if err := w.emit(w.buf[:idx+1]); err != nil {
+			return 0, err
+		}
+		w.buf = w.buf[idx+1:]
+	}
+
+	return len(p), nil
+}
+
+// Flush writes any trailing partial line, terminating it with a newline.
+func (w *prefixWriter) Flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	line := append(w.buf, '\n')
+	w.buf = nil
+	return w.emit(line)
+}
+
+func (w *prefixWriter) emit(line []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, err := io.WriteString(w.out, w.prefix); err != nil {
+		return err
+	}
+	_, err := w.out.Write(line)
+	return err
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package runner
+
+import "os/exec"
+
+// configureProcessGroup is a no-op on platforms without process groups;
+// cancellation falls back to killing the shell process only.
+func configureProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,47 @@
+package runner
+
+import "time"
+
+// JobStatus is the final state of a job after a run
+type JobStatus string
+
+const (
+	// JobPending means the job has not started yet
+	JobPending JobStatus = "pending"
+	// JobWaitingApproval means the job's gates have not been approved yet
+	JobWaitingApproval JobStatus = "waiting-approval"
+	// JobRunning means the job is executing
+	JobRunning JobStatus = "running"
+	// JobSucceeded means every step of the job succeeded
+	JobSucceeded JobStatus = "succeeded"
+	// JobSucceededWithWarnings means the job completed but at least one
+	// onFailure: continue step failed
+	JobSucceededWithWarnings JobStatus = "succeeded-with-warnings"
+	// JobFailed means a step failed and the job was stopped
+	JobFailed JobStatus = "failed"
+	// JobSkipped means a dependency condition was not met
+	JobSkipped JobStatus = "skipped"
+	// JobNotRun means the job was never started because the run stopped early
+	JobNotRun JobStatus = "not-run"
+)
+
+// Succeeded reports whether the status counts as a successful job
+func (s JobStatus) Succeeded() bool {
+	return s == JobSucceeded || s == JobSucceededWithWarnings
+}
+
+// JobResult records the outcome of a job in a run
+type JobResult struct {
+	JobID    string
+	Status   JobStatus
+	Attempts int
+	Warnings []string // Failures of onFailure: continue steps
+	Reason   string   // Why a job was skipped or not run
+	Err      error
+
+	StartedAt  time.Time
+	FinishedAt time.Time
+	ExitCode   int // Exit code of the last step run; -1 if the step was killed or never exited
+
+	previous bool // Result restored from the run state rather than executed
+}
@@ -0,0 +1,48 @@
+package runner
+
+import (
+	"context"
+	"time"
+)
+
+// Default backoff between retry attempts; the delay doubles after every
+// failed attempt up to the configured maximum.
+const (
+	DefaultRetryBackoff    = 2 * time.Second
+	DefaultRetryBackoffMax = 1 * time.Minute
+)
+
+// retryDelay returns the wait before the given retry (1 for the first retry).
+func (r *Runner) retryDelay(retry int) time.Duration {
+	delay := r.RetryBackoff
+	if delay <= 0 {
+		return 0
+	}
+	for i := 1; i < retry; i++ {
+		delay *= 2
+		if r.RetryBackoffMax > 0 && delay >= r.RetryBackoffMax {
+			return r.RetryBackoffMax
+		}
+	}
+	if r.RetryBackoffMax > 0 && delay > r.RetryBackoffMax {
+		return r.RetryBackoffMax
+	}
+	return delay
+}
+
+// sleepContext waits for d or until ctx is done, reporting whether the full
+// delay elapsed.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
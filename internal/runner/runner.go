@@ -1,32 +1,80 @@
 package runner
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os/exec"
 	"path/filepath"
 	"sort"
+	"sync"
+	"time"
 
 	"github.com/sourceplane/liteci/internal/model"
+	"github.com/sourceplane/liteci/internal/render"
 )
 
 // Runner executes a compiled plan in dependency order.
+// Jobs whose dependencies are satisfied run concurrently, bounded by MaxParallel.
+// Failed steps and jobs are retried according to PlanStep.Retry and
+// PlanJob.Retries, waiting RetryBackoff (doubling up to RetryBackoffMax)
+// between attempts. With KeepGoing, a failed job only skips its transitive
+// dependents and every independent branch of the DAG still runs.
+// When State is set, progress is recorded in it and saved to StatePath after
+// every job transition; jobs the state already marks as succeeded are not
+// run again.
+// Jobs with gates wait, without holding a MaxParallel slot, until they are
+// approved at the Prompt or through the approvals file at ApprovalsPath.
 type Runner struct {
-	WorkDir string
-	Stdout  io.Writer
-	Stderr  io.Writer
-	DryRun  bool
+	WorkDir         string
+	Stdout          io.Writer
+	Stderr          io.Writer
+	DryRun          bool
+	MaxParallel     int
+	RetryBackoff    time.Duration
+	RetryBackoffMax time.Duration
+	KeepGoing       bool
+	State           *RunState
+	StatePath       string
+	ApprovalsPath   string
+	ApprovalPoll    time.Duration
+	ApprovalTimeout time.Duration // 0 waits for approval indefinitely
+	Prompt          io.Reader     // When set, gates are approved interactively
+
+	outputMu    sync.Mutex // serializes line writes to Stdout and Stderr
+	promptOnce  sync.Once
+	promptTurn  chan struct{} // holds one token; the gate holding it owns the prompt
+	promptLines chan string   // lines read from Prompt; closed at EOF
+	results     []JobResult
+}
+
+// approvalResult is the outcome of waiting on a gated job
+type approvalResult struct {
+	jobID    string
+	approval *Approval
+	err      error
 }
 
 func NewRunner(workDir string, stdout, stderr io.Writer, dryRun bool) *Runner {
 	return &Runner{
-		WorkDir: workDir,
-		Stdout:  stdout,
-		Stderr:  stderr,
-		DryRun:  dryRun,
+		WorkDir:         workDir,
+		Stdout:          stdout,
+		Stderr:          stderr,
+		DryRun:          dryRun,
+		MaxParallel:     1,
+		RetryBackoff:    DefaultRetryBackoff,
+		RetryBackoffMax: DefaultRetryBackoffMax,
+		ApprovalPoll:    DefaultApprovalPoll,
 	}
 }
 
+// Run schedules every job whose dependencies have completed, keeping at most
+// MaxParallel jobs in flight. A job runs only if the condition on each of its
+// dependency edges (success, always, failure) is met; otherwise it is skipped.
+// After the first failure no new jobs are started except those reached through
+// an always or failure edge, such as cleanup jobs, unless KeepGoing is set.
+// Jobs already running are allowed to finish before the error is returned.
 func (r *Runner) Run(plan *model.Plan) error {
 	if plan == nil {
 		return fmt.Errorf("plan cannot be nil")
@@ -37,29 +85,392 @@ func (r *Runner) Run(plan *model.Plan) error {
 		return err
 	}
 
+	if err := validatePlan(plan.Jobs); err != nil {
+		return err
+	}
+
+	if r.State != nil {
+		if err := r.State.prepare(plan, orderedJobs); err != nil {
+			return err
+		}
+		r.saveState()
+	}
+
+	digest, err := render.ContentDigest(plan)
+	if err != nil {
+		return err
+	}
+
+	maxParallel := r.MaxParallel
+	if maxParallel < 1 {
+		maxParallel = 1
+	}
+
+	// Ready jobs are started in topological order so output stays deterministic
+	// when MaxParallel is 1.
+	position := make(map[string]int, len(orderedJobs))
+	jobsByID := make(map[string]model.PlanJob, len(orderedJobs))
+	remaining := make(map[string]int, len(orderedJobs))
+	dependents := make(map[string][]string, len(orderedJobs))
+	for i, job := range orderedJobs {
+		position[job.ID] = i
+		jobsByID[job.ID] = job
+		remaining[job.ID] = len(job.DependsOn)
+		for _, dep := range job.DependsOn {
+			dependents[dep] = append(dependents[dep], job.ID)
+		}
+	}
+
+	ready := make([]string, 0)
 	for _, job := range orderedJobs {
-		fmt.Fprintf(r.Stdout, "→ Job %s (%s/%s)\n", job.ID, job.Component, job.Environment)
-		for _, step := range job.Steps {
-			fmt.Fprintf(r.Stdout, "  - Step %s\n", step.Name)
-			if r.DryRun {
-				fmt.Fprintf(r.Stdout, "    %s\n", step.Run)
+		if remaining[job.ID] == 0 {
+			ready = append(ready, job.ID)
+		}
+	}
+
+	results := make(chan JobResult)
+	byID := make(map[string]JobResult, len(orderedJobs))
+	running := 0
+	stopped := false
+	var failures []error
+
+	// Gated jobs wait for approval in the background; approved jobs rejoin
+	// the ready queue. Stopping the run abandons pending approvals.
+	approvals := make(chan approvalResult)
+	approved := make(map[string]bool)
+	waiting := 0
+	approvalCtx, cancelApprovals := context.WithCancel(context.Background())
+	defer cancelApprovals()
+
+	// settle records a final result and releases dependents whose
+	// dependencies are now all finished, skipping those whose conditions fail.
+	var settle func(result JobResult)
+	settle = func(result JobResult) {
+		byID[result.JobID] = result
+		if r.State != nil && !result.previous {
+			r.State.record(result)
+			r.saveState()
+		}
+		if result.Status == JobFailed {
+			failures = append(failures, result.Err)
+			stopped = !r.KeepGoing
+			if stopped {
+				cancelApprovals()
+			}
+		}
+
+		for _, dependent := range dependents[result.JobID] {
+			remaining[dependent]--
+			if remaining[dependent] > 0 {
+				continue
+			}
+			if reason := unmetCondition(jobsByID[dependent], byID); reason != "" {
+				r.printf("⊘ Skipping job %s: %s\n", dependent, reason)
+				settle(JobResult{JobID: dependent, Status: JobSkipped, Reason: reason})
 				continue
 			}
+			ready = append(ready, dependent)
+		}
+		sort.Slice(ready, func(i, j int) bool {
+			return position[ready[i]] < position[ready[j]]
+		})
+	}
+
+	for len(ready) > 0 || running > 0 || waiting > 0 {
+		for running < maxParallel && len(ready) > 0 {
+			job := jobsByID[ready[0]]
+			ready = ready[1:]
 
-			cmd := exec.Command("sh", "-c", step.Run)
-			cmd.Dir = r.resolveWorkingDir(job.Path)
-			cmd.Stdout = r.Stdout
-			cmd.Stderr = r.Stderr
+			if r.State != nil {
+				if previous, ok := r.State.completed(job.ID); ok {
+					r.printf("✓ Job %s already completed in a previous run\n", job.ID)
+					previous.previous = true
+					settle(previous)
+					continue
+				}
+			}
 
-			if err := cmd.Run(); err != nil {
-				return fmt.Errorf("job %s step %s failed: %w", job.ID, step.Name, err)
+			if stopped && !hasConditionalEdge(job) {
+				r.printf("⊘ Not starting job %s: run stopped after a job failed\n", job.ID)
+				settle(JobResult{JobID: job.ID, Status: JobNotRun, Reason: "run stopped after a job failed"})
+				continue
 			}
+
+			if len(job.Gates) > 0 && !approved[job.ID] {
+				if approval := r.State.approval(job.ID); approval != nil {
+					approved[job.ID] = true
+				} else {
+					if r.State != nil {
+						r.State.markWaiting(job.ID)
+						r.saveState()
+					}
+					waiting++
+					go func(job model.PlanJob) {
+						approval, err := r.awaitApproval(approvalCtx, job, digest)
+						approvals <- approvalResult{jobID: job.ID, approval: approval, err: err}
+					}(job)
+					continue
+				}
+			}
+
+			if r.State != nil {
+				r.State.markRunning(job.ID)
+				r.saveState()
+			}
+
+			running++
+			go func(job model.PlanJob) {
+				results <- r.runJob(job)
+			}(job)
+		}
+
+		if running == 0 && waiting == 0 {
+			break
 		}
+
+		select {
+		case result := <-results:
+			running--
+			settle(result)
+		case result := <-approvals:
+			waiting--
+			switch {
+			case result.err == nil:
+				approved[result.jobID] = true
+				if r.State != nil {
+					r.State.approve(result.jobID, result.approval)
+					r.saveState()
+				}
+				if result.approval.Via != "dry-run" {
+					r.printf("✓ Job %s approved by %s (%s)\n", result.jobID, result.approval.ApprovedBy, result.approval.Via)
+				}
+				ready = append(ready, result.jobID)
+				sort.Slice(ready, func(i, j int) bool {
+					return position[ready[i]] < position[ready[j]]
+				})
+			case stopped:
+				r.printf("⊘ Not starting job %s: run stopped after a job failed\n", result.jobID)
+				settle(JobResult{JobID: result.jobID, Status: JobNotRun, Reason: "run stopped after a job failed"})
+			default:
+				r.printf("✗ %v\n", result.err)
+				settle(JobResult{JobID: result.jobID, Status: JobFailed, Err: result.err, Reason: "not approved"})
+			}
+		}
+	}
+
+	r.results = make([]JobResult, 0, len(orderedJobs))
+	for _, job := range orderedJobs {
+		r.results = append(r.results, byID[job.ID])
+	}
+
+	if len(failures) == 1 {
+		return failures[0]
+	}
+	if len(failures) > 1 {
+		return fmt.Errorf("%d jobs failed; first error: %w", len(failures), failures[0])
 	}
 
 	return nil
 }
 
+// saveState persists the run state; dry runs never touch the state file.
+// A failed write is reported but does not stop the run.
+func (r *Runner) saveState() {
+	if r.DryRun || r.StatePath == "" {
+		return
+	}
+	if err := r.State.Save(r.StatePath); err != nil {
+		r.printf("⚠ %v\n", err)
+	}
+}
+
+// printf writes a runner message to Stdout without interleaving job output.
+func (r *Runner) printf(format string, args ...interface{}) {
+	r.outputMu.Lock()
+	defer r.outputMu.Unlock()
+	fmt.Fprintf(r.Stdout, format, args...)
+}
+
+// Results returns the outcome of every job from the last Run, in execution order.
+func (r *Runner) Results() []JobResult {
+	return r.results
+}
+
+// runJob executes a job, retrying the whole job up to job.Retries times.
+// Every attempt is reported in the job's prefixed output.
+func (r *Runner) runJob(job model.PlanJob) JobResult {
+	prefix := fmt.Sprintf("[%s] ", job.ID)
+	stdout := newPrefixWriter(r.Stdout, &r.outputMu, prefix)
+	stderr := newPrefixWriter(r.Stderr, &r.outputMu, prefix)
+	defer stdout.Flush()
+	defer stderr.Flush()
+
+	fmt.Fprintf(stdout, "→ Job %s (%s/%s)\n", job.ID, job.Component, job.Environment)
+
+	attempts := 1
+	if !r.DryRun && job.Retries > 0 {
+		attempts += job.Retries
+	}
+
+	result := JobResult{JobID: job.ID, StartedAt: time.Now()}
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			delay := r.retryDelay(attempt - 1)
+			fmt.Fprintf(stdout, "↻ Retrying job (attempt %d/%d) in %s\n", attempt, attempts, delay)
+			sleepContext(context.Background(), delay)
+		}
+
+		result.Attempts = attempt
+		warnings, err := r.runJobAttempt(job, stdout, stderr)
+		result.Warnings = warnings
+		result.Err = err
+		if err == nil {
+			break
+		}
+		if attempts > 1 {
+			fmt.Fprintf(stdout, "✗ Job attempt %d/%d failed: %v\n", attempt, attempts, err)
+		}
+	}
+
+	result.FinishedAt = time.Now()
+	result.ExitCode = exitCode(result.Err)
+
+	switch {
+	case result.Err != nil:
+		result.Status = JobFailed
+		if attempts > 1 {
+			result.Err = fmt.Errorf("job %s failed after %d attempts: %w", job.ID, attempts, result.Err)
+		}
+	case len(result.Warnings) > 0:
+		result.Status = JobSucceededWithWarnings
+		fmt.Fprintf(stdout, "⚠ Job succeeded with %d warning(s)\n", len(result.Warnings))
+	default:
+		result.Status = JobSucceeded
+	}
+
+	return result
+}
+
+// runJobAttempt runs all steps of a job once. The job timeout bounds the whole
+// attempt; each step may set a tighter timeout and retry up to step.Retry times.
+// Failures of onFailure: continue steps are returned as warnings instead of
+// stopping the job, unless the job itself ran out of time.
+func (r *Runner) runJobAttempt(job model.PlanJob, stdout, stderr io.Writer) ([]string, error) {
+	// Timeouts were validated before scheduling.
+	jobTimeout, _ := parseTimeout(job.Timeout)
+	jobCtx, cancel := withOptionalTimeout(context.Background(), jobTimeout)
+	defer cancel()
+
+	var warnings []string
+	for _, step := range job.Steps {
+		fmt.Fprintf(stdout, "  - Step %s\n", step.Name)
+		if r.DryRun {
+			fmt.Fprintf(stdout, "    %s\n", step.Run)
+			continue
+		}
+
+		stepTimeout, _ := parseTimeout(step.Timeout)
+		attempts := 1 + step.Retry
+		if step.Retry < 0 {
+			attempts = 1
+		}
+
+		var err error
+		for attempt := 1; attempt <= attempts; attempt++ {
+			if attempt > 1 {
+				delay := r.retryDelay(attempt - 1)
+				fmt.Fprintf(stdout, "    ↻ Retrying step %s (attempt %d/%d) in %s\n", step.Name, attempt, attempts, delay)
+				if !sleepContext(jobCtx, delay) {
+					err = &TimeoutError{JobID: job.ID, Step: step.Name, Scope: "job", Timeout: jobTimeout}
+					break
+				}
+			}
+
+			err = r.runStep(jobCtx, job, step, stepTimeout, jobTimeout, stdout, stderr)
+			if err == nil {
+				break
+			}
+			if attempts > 1 {
+				fmt.Fprintf(stdout, "    ✗ Step attempt %d/%d failed: %v\n", attempt, attempts, err)
+			}
+			// Once the job budget is spent there is no point retrying the step.
+			if jobCtx.Err() != nil {
+				break
+			}
+		}
+
+		if err == nil {
+			continue
+		}
+		if step.OnFailure == OnFailureContinue && jobCtx.Err() == nil {
+			fmt.Fprintf(stdout, "    ⚠ Step %s failed, continuing (onFailure: continue): %v\n", step.Name, err)
+			warnings = append(warnings, err.Error())
+			continue
+		}
+		return warnings, err
+	}
+
+	return warnings, nil
+}
+
+// runStep runs a single step command, killing its process group when the step
+// or the enclosing job runs out of time.
+func (r *Runner) runStep(jobCtx context.Context, job model.PlanJob, step model.PlanStep, stepTimeout, jobTimeout time.Duration, stdout, stderr io.Writer) error {
+	env, err := stepEnvironment(job, step)
+	if err != nil {
+		return err
+	}
+
+	stepCtx, cancel := withOptionalTimeout(jobCtx, stepTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(stepCtx, "sh", "-c", step.Run)
+	cmd.Dir = r.resolveWorkingDir(job.Path)
+	cmd.Env = env
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// Background processes may keep the output pipes open after the group is
+	// killed; don't let them block Wait forever.
+	cmd.WaitDelay = 5 * time.Second
+	configureProcessGroup(cmd)
+
+	err = cmd.Run()
+	if err == nil {
+		return nil
+	}
+
+	if jobCtx.Err() == context.DeadlineExceeded {
+		return &TimeoutError{JobID: job.ID, Step: step.Name, Scope: "job", Timeout: jobTimeout}
+	}
+	if stepCtx.Err() == context.DeadlineExceeded {
+		return &TimeoutError{JobID: job.ID, Step: step.Name, Scope: "step", Timeout: stepTimeout}
+	}
+
+	return fmt.Errorf("job %s step %s failed: %w", job.ID, step.Name, err)
+}
+
+// exitCode extracts the process exit code from a step error: 0 for success,
+// the code of an exited process, or -1 when the process was killed or never ran.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// withOptionalTimeout derives a context with a deadline, or a plain cancelable
+// context when timeout is zero.
+func withOptionalTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, timeout)
+}
+
 func (r *Runner) resolveWorkingDir(path string) string {
 	if path == "" || path == "./" {
 		return r.WorkDir
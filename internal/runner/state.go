@@ -0,0 +1,184 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sourceplane/liteci/internal/model"
+	"github.com/sourceplane/liteci/internal/render"
+)
+
+// RunState is the persisted progress of a plan execution, written next to the
+// plan so an interrupted run can be resumed.
+type RunState struct {
+	APIVersion string               `json:"apiVersion"`
+	Kind       string               `json:"kind"`
+	PlanDigest string               `json:"planDigest"` // Content digest (metadata.digest) of the plan the state belongs to
+	StartedAt  time.Time            `json:"startedAt"`
+	UpdatedAt  time.Time            `json:"updatedAt"`
+	Jobs       map[string]*JobState `json:"jobs"`
+}
+
+// JobState is the persisted progress of a single job
+type JobState struct {
+	Status     JobStatus  `json:"status"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	ExitCode   *int       `json:"exitCode,omitempty"`
+	Attempts   int        `json:"attempts,omitempty"`
+	Warnings   []string   `json:"warnings,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	Reason     string     `json:"reason,omitempty"`   // Why the job was skipped or not run
+	Approval   *Approval  `json:"approval,omitempty"` // Who unlocked the job's gates
+}
+
+// NewRunState creates an empty run state
+func NewRunState() *RunState {
+	now := time.Now().UTC()
+	return &RunState{
+		APIVersion: "sourceplane.io/v1",
+		Kind:       "RunState",
+		StartedAt:  now,
+		UpdatedAt:  now,
+		Jobs:       make(map[string]*JobState),
+	}
+}
+
+// DefaultStatePath returns the run state file that sits next to a plan file,
+// e.g. plan.json -> plan.state.json
+func DefaultStatePath(planPath string) string {
+	ext := filepath.Ext(planPath)
+	return strings.TrimSuffix(planPath, ext) + ".state.json"
+}
+
+// LoadRunState reads a run state file
+func LoadRunState(path string) (*RunState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run state %s: %w", path, err)
+	}
+
+	var state RunState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse run state %s: %w", path, err)
+	}
+	if state.Jobs == nil {
+		state.Jobs = make(map[string]*JobState)
+	}
+
+	return &state, nil
+}
+
+// Save atomically writes the run state to path
+func (s *RunState) Save(path string) error {
+	s.UpdatedAt = time.Now().UTC()
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to render run state: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write run state to %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write run state to %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// prepare binds the state to plan, rejecting a state written for another plan
+// and resetting every job that has not completed to pending.
+func (s *RunState) prepare(plan *model.Plan, jobs []model.PlanJob) error {
+	digest, err := render.ContentDigest(plan)
+	if err != nil {
+		return err
+	}
+	if s.PlanDigest != "" && s.PlanDigest != digest {
+		return fmt.Errorf("plan has changed since the run state was written (state %s, plan %s); rerun without --resume", s.PlanDigest, digest)
+	}
+	s.PlanDigest = digest
+
+	for _, job := range jobs {
+		if existing, ok := s.Jobs[job.ID]; ok && existing.Status.Succeeded() {
+			continue
+		}
+		s.Jobs[job.ID] = &JobState{Status: JobPending, Approval: s.approval(job.ID)}
+	}
+
+	return nil
+}
+
+// completed returns the previously recorded result of a job that already
+// succeeded, if any
+func (s *RunState) completed(jobID string) (JobResult, bool) {
+	job, ok := s.Jobs[jobID]
+	if !ok || !job.Status.Succeeded() {
+		return JobResult{}, false
+	}
+	return JobResult{
+		JobID:    jobID,
+		Status:   job.Status,
+		Attempts: job.Attempts,
+		Warnings: job.Warnings,
+		Reason:   "completed in a previous run",
+	}, true
+}
+
+// approval returns the approval recorded for a gated job, if any. It is safe
+// to call on a nil state.
+func (s *RunState) approval(jobID string) *Approval {
+	if s == nil {
+		return nil
+	}
+	if job, ok := s.Jobs[jobID]; ok {
+		return job.Approval
+	}
+	return nil
+}
+
+func (s *RunState) markWaiting(jobID string) {
+	s.Jobs[jobID] = &JobState{Status: JobWaitingApproval}
+}
+
+func (s *RunState) approve(jobID string, approval *Approval) {
+	job, ok := s.Jobs[jobID]
+	if !ok {
+		job = &JobState{Status: JobPending}
+		s.Jobs[jobID] = job
+	}
+	job.Approval = approval
+}
+
+func (s *RunState) markRunning(jobID string) {
+	now := time.Now().UTC()
+	s.Jobs[jobID] = &JobState{Status: JobRunning, StartedAt: &now, Approval: s.approval(jobID)}
+}
+
+func (s *RunState) record(result JobResult) {
+	job := &JobState{
+		Status:   result.Status,
+		Attempts: result.Attempts,
+		Warnings: result.Warnings,
+	}
+	if !result.StartedAt.IsZero() {
+		startedAt := result.StartedAt.UTC()
+		finishedAt := result.FinishedAt.UTC()
+		exitCode := result.ExitCode
+		job.StartedAt = &startedAt
+		job.FinishedAt = &finishedAt
+		job.ExitCode = &exitCode
+	}
+	if result.Err != nil {
+		job.Error = result.Err.Error()
+	}
+	job.Reason = result.Reason
+	job.Approval = s.approval(result.JobID)
+	s.Jobs[result.JobID] = job
+}
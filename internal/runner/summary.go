@@ -0,0 +1,45 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// WriteSummary writes a table of every job's final status from the last Run,
+// followed by per-status totals.
+func (r *Runner) WriteSummary(w io.Writer) error {
+	if len(r.results) == 0 {
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "JOB\tSTATUS\tATTEMPTS\tDETAIL")
+
+	counts := make(map[JobStatus]int)
+	for _, result := range r.results {
+		counts[result.Status]++
+
+		attempts := "-"
+		if result.Attempts > 0 {
+			attempts = fmt.Sprintf("%d", result.Attempts)
+		}
+
+		detail := result.Reason
+		switch {
+		case result.Err != nil:
+			detail = result.Err.Error()
+		case len(result.Warnings) > 0:
+			detail = fmt.Sprintf("%d warning(s)", len(result.Warnings))
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", result.JobID, result.Status, attempts, detail)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "\n%d succeeded, %d succeeded with warnings, %d failed, %d skipped, %d not run\n",
+		counts[JobSucceeded], counts[JobSucceededWithWarnings], counts[JobFailed], counts[JobSkipped], counts[JobNotRun])
+	return err
+}
@@ -0,0 +1,34 @@
+package runner
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeoutError reports a step or job that exceeded its configured budget.
+type TimeoutError struct {
+	JobID   string
+	Step    string
+	Scope   string // "step" or "job"
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("job %s step %s timed out after %s (%s timeout)", e.JobID, e.Step, e.Timeout, e.Scope)
+}
+
+// parseTimeout converts a plan timeout string (e.g. "15m") into a duration.
+// An empty string means no timeout.
+func parseTimeout(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("timeout must not be negative")
+	}
+	return d, nil
+}
@@ -0,0 +1,62 @@
+package runner
+
+import (
+	"fmt"
+
+	"github.com/sourceplane/liteci/internal/model"
+)
+
+// Step failure policies accepted in PlanStep.OnFailure
+const (
+	OnFailureStop     = "stop"
+	OnFailureContinue = "continue"
+)
+
+// validatePlan checks step settings the runner interprets before anything
+// runs, so a typo fails the run up front instead of halfway through.
+func validatePlan(jobs []model.PlanJob) error {
+	if err := validateTimeouts(jobs); err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		// Fail before anything runs rather than at the first step of the job
+		if _, err := inputVars(job); err != nil {
+			return fmt.Errorf("job %s: %w", job.ID, err)
+		}
+
+		for dep, condition := range job.Conditions {
+			switch condition {
+			case "", model.ConditionSuccess, model.ConditionAlways, model.ConditionFailure:
+			default:
+				return fmt.Errorf("job %s has invalid condition %q on dependency %s", job.ID, condition, dep)
+			}
+		}
+
+		for _, step := range job.Steps {
+			switch step.OnFailure {
+			case "", OnFailureStop, OnFailureContinue:
+			default:
+				return fmt.Errorf("job %s step %s has invalid onFailure %q (expected %s or %s)",
+					job.ID, step.Name, step.OnFailure, OnFailureStop, OnFailureContinue)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateTimeouts checks that every job and step timeout parses as a duration.
+func validateTimeouts(jobs []model.PlanJob) error {
+	for _, job := range jobs {
+		if _, err := parseTimeout(job.Timeout); err != nil {
+			return fmt.Errorf("job %s has invalid timeout %q: %w", job.ID, job.Timeout, err)
+		}
+		for _, step := range job.Steps {
+			if _, err := parseTimeout(step.Timeout); err != nil {
+				return fmt.Errorf("job %s step %s has invalid timeout %q: %w", job.ID, step.Name, step.Timeout, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SignatureExt is appended to a plan path to name its detached signature,
+// e.g. plan.json -> plan.json.sig
+const SignatureExt = ".sig"
+
+// SignaturePath returns the detached signature file of a plan file
+func SignaturePath(planPath string) string {
+	return planPath + SignatureExt
+}
+
+// LoadPrivateKey reads a PEM-encoded PKCS #8 ed25519 private key, as written by
+// openssl genpkey -algorithm ed25519
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	der, err := readPEM(path, "PRIVATE KEY")
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key %s: %w", path, err)
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key %s is not an ed25519 key", path)
+	}
+	return edKey, nil
+}
+
+// LoadPublicKey reads a PEM-encoded PKIX ed25519 public key, as written by
+// openssl pkey -pubout
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	der, err := readPEM(path, "PUBLIC KEY")
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key %s: %w", path, err)
+	}
+	edKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key %s is not an ed25519 key", path)
+	}
+	return edKey, nil
+}
+
+// Sign returns the base64 ed25519 signature of data, as stored in a
+// signature file
+func Sign(data []byte, key ed25519.PrivateKey) []byte {
+	signature := ed25519.Sign(key, data)
+	return []byte(base64.StdEncoding.EncodeToString(signature) + "\n")
+}
+
+// WriteSignature signs data and writes the detached signature to path
+func WriteSignature(path string, data []byte, key ed25519.PrivateKey) error {
+	if err := os.WriteFile(path, Sign(data, key), 0644); err != nil {
+		return fmt.Errorf("failed to write signature to %s: %w", path, err)
+	}
+	return nil
+}
+
+// Verify checks data against the detached signature stored at sigPath
+func Verify(data []byte, sigPath string, key ed25519.PublicKey) error {
+	encoded, err := os.ReadFile(sigPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("plan is not signed: signature %s not found", sigPath)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read signature %s: %w", sigPath, err)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return fmt.Errorf("signature %s is not a base64 ed25519 signature", sigPath)
+	}
+	if !ed25519.Verify(key, data, signature) {
+		return fmt.Errorf("plan signature %s is not valid for this plan and key; the plan was modified or signed with another key", sigPath)
+	}
+	return nil
+}
+
+func readPEM(path, blockType string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key %s: %w", path, err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != blockType {
+		return nil, fmt.Errorf("key %s is not a PEM %q block", path, blockType)
+	}
+	return block.Bytes, nil
+}